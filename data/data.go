@@ -40,6 +40,7 @@ type Query struct {
 // Area TODO:
 type Area struct {
 	XMLName xml.Name `xml:"area"`
+	Pos     Position `xml:"urn:position pos,attr"`
 
 	Name         string `xml:"name,attr"`
 	Number       string `xml:"number,attr"`
@@ -57,13 +58,35 @@ type Area struct {
 // Data TODO:
 type Data struct {
 	XMLName      xml.Name      `xml:"dataTypes"`
+	Fundamentals []Fundamental `xml:"fundamental"`
+	Attributes   []Attribute   `xml:"attribute"`
 	Enumerations []Enumeration `xml:"enumeration"`
 	Composites   []Composite   `xml:"composite"`
 }
 
+// Fundamental is one of the base types of the MAL (Element, Attribute
+// and Composite)
+type Fundamental struct {
+	XMLName xml.Name `xml:"fundamental"`
+	Pos     Position `xml:"urn:position pos,attr"`
+	Name    string   `xml:"name,attr"`
+	Comment string   `xml:"comment,attr"`
+	Extend  Extends  `xml:"extends"`
+}
+
+// Attribute is a MAL attribute type (Blob, Boolean, ..., URI)
+type Attribute struct {
+	XMLName       xml.Name `xml:"attribute"`
+	Pos           Position `xml:"urn:position pos,attr"`
+	Name          string   `xml:"name,attr"`
+	ShortFormPart string   `xml:"shortFormPart,attr"`
+	Comment       string   `xml:"comment,attr"`
+}
+
 // Enumeration TODO:
 type Enumeration struct {
 	XMLName       xml.Name `xml:"enumeration"`
+	Pos           Position `xml:"urn:position pos,attr"`
 	Name          string   `xml:"name,attr"`
 	ShortFormPart string   `xml:"shortFormPart,attr"`
 	Comment       string   `xml:"comment,attr"`
@@ -73,6 +96,7 @@ type Enumeration struct {
 // Item TODO:
 type Item struct {
 	XMLName xml.Name `xml:"item"`
+	Pos     Position `xml:"urn:position pos,attr"`
 	Value   string   `xml:"value,attr"`
 	NValue  string   `xml:"nvalue,attr"`
 	Comment string   `xml:"comment,attr"`
@@ -81,6 +105,7 @@ type Item struct {
 // Composite TODO:
 type Composite struct {
 	XMLName       xml.Name `xml:"composite"`
+	Pos           Position `xml:"urn:position pos,attr"`
 	Name          string   `xml:"name,attr"`
 	ShortFormPart string   `xml:"shortFormPart,attr"`
 	Comment       string   `xml:"comment,attr"`
@@ -97,6 +122,7 @@ type Extends struct {
 // Field TODO:
 type Field struct {
 	XMLName        xml.Name `xml:"field"`
+	Pos            Position `xml:"urn:position pos,attr"`
 	Name           string   `xml:"name,attr"`
 	FieldCanBeNull string   `xml:"canBeNull,attr"`
 	Comment        string   `xml:"comment,attr"`
@@ -111,6 +137,7 @@ func (f Field) CanBeNull() bool {
 // Type TODO:
 type Type struct {
 	XMLName xml.Name `xml:"type"`
+	Pos     Position `xml:"urn:position pos,attr"`
 	List    string   `xml:"list,attr"`
 	Name    string   `xml:"name,attr"`
 	Service string   `xml:"service,attr"`
@@ -132,10 +159,43 @@ type Errors struct {
 
 // Error TODO:
 type Error struct {
-	XMLName xml.Name `xml:"error"`
-	Name    string   `xml:"name,attr"`
-	Number  string   `xml:"number,attr"`
-	Comment string   `xml:"comment,attr"`
+	XMLName          xml.Name         `xml:"error"`
+	Pos              Position         `xml:"urn:position pos,attr"`
+	Name             string           `xml:"name,attr"`
+	Number           string           `xml:"number,attr"`
+	Comment          string           `xml:"comment,attr"`
+	ExtraInformation ExtraInformation `xml:"extraInformation"`
+}
+
+// ExtraInformation is the type of the extra information returned with
+// an error
+type ExtraInformation struct {
+	XMLName   xml.Name `xml:"extraInformation"`
+	Comment   string   `xml:"comment,attr"`
+	ExtraType Type     `xml:"type"`
+}
+
+// OperationErrors is the list of the errors an operation may raise
+type OperationErrors struct {
+	XMLName xml.Name         `xml:"errors"`
+	Errs    []OperationError `xml:",any"`
+}
+
+// OperationError is either the definition of a new error (error) or a
+// reference to an existing one (errorRef)
+type OperationError struct {
+	XMLName          xml.Name
+	Pos              Position         `xml:"urn:position pos,attr"`
+	Name             string           `xml:"name,attr"`
+	Number           string           `xml:"number,attr"`
+	Comment          string           `xml:"comment,attr"`
+	ErrorType        Type             `xml:"type"`
+	ExtraInformation ExtraInformation `xml:"extraInformation"`
+}
+
+// IsReference checks if the error is a reference to an existing error
+func (e OperationError) IsReference() bool {
+	return e.XMLName.Local == "errorRef"
 }
 
 // --------------------- SERVICE --------------------
@@ -143,6 +203,7 @@ type Error struct {
 // Service structure to describe a service
 type Service struct {
 	XMLName xml.Name `xml:"service"`
+	Pos     Position `xml:"urn:position pos,attr"`
 
 	Name    string `xml:"name,attr"`
 	Number  string `xml:"number,attr"`
@@ -150,6 +211,62 @@ type Service struct {
 
 	Capability []CapabilitySet `xml:"capabilitySet"`
 	Datas      Data            `xml:"dataTypes"`
+	Errs       Errors          `xml:"errors"`
+	// COM features of the services of type com:ExtendedServiceType
+	Features Features `xml:"features"`
+}
+
+// ---------------------- COM -----------------------
+
+// Features are the COM features supported by a service
+type Features struct {
+	XMLName       xml.Name `xml:"features"`
+	Objects       Objects  `xml:"objects"`
+	Events        Events   `xml:"events"`
+	ArchiveUsage  *Usage   `xml:"archiveUsage"`
+	ActivityUsage *Usage   `xml:"activityUsage"`
+}
+
+// Objects is the list of the COM objects defined by a service
+type Objects struct {
+	XMLName xml.Name      `xml:"objects"`
+	Comment string        `xml:"comment,attr"`
+	Objects []ModelObject `xml:"object"`
+}
+
+// Events is the list of the COM events defined by a service
+type Events struct {
+	XMLName xml.Name      `xml:"events"`
+	Comment string        `xml:"comment,attr"`
+	Events  []ModelObject `xml:"event"`
+}
+
+// ModelObject is a COM object or event
+type ModelObject struct {
+	Pos           Position         `xml:"urn:position pos,attr"`
+	Name          string           `xml:"name,attr"`
+	Number        string           `xml:"number,attr"`
+	Comment       string           `xml:"comment,attr"`
+	ObjectType    Type             `xml:"objectType>type"`
+	RelatedObject *ObjectReference `xml:"relatedObject"`
+	SourceObject  *ObjectReference `xml:"sourceObject"`
+}
+
+// ObjectReference describes the related or the source object of a COM
+// object, the type of the referenced object is optional
+type ObjectReference struct {
+	Comment    string `xml:"comment,attr"`
+	ObjectType struct {
+		Area    string `xml:"area,attr"`
+		Service string `xml:"service,attr"`
+		Number  string `xml:"number,attr"`
+	} `xml:"objectType"`
+}
+
+// Usage describes how a service uses the COM archive or activity
+// tracking
+type Usage struct {
+	Comment string `xml:"comment,attr"`
 }
 
 // CapabilitySet TODO:
@@ -195,9 +312,10 @@ func (cap CapabilitySet) PrintAllOperations() {
 
 // Operation TODO:
 type Operation struct {
-	Name    string `xml:"name,attr"`
-	Number  string `xml:"number,attr"`
-	Comment string `xml:"comment,attr"`
+	Pos     Position `xml:"urn:position pos,attr"`
+	Name    string   `xml:"name,attr"`
+	Number  string   `xml:"number,attr"`
+	Comment string   `xml:"comment,attr"`
 }
 
 func (op Operation) printOperation() {
@@ -221,36 +339,41 @@ type SendIP struct {
 // SubmitIP TODO:
 type SubmitIP struct {
 	Operation
-	XMLName xml.Name `xml:"submitIP"`
-	Message Messages `xml:"messages"`
+	XMLName xml.Name        `xml:"submitIP"`
+	Message Messages        `xml:"messages"`
+	Errs    OperationErrors `xml:"errors"`
 }
 
 // RequestIP TODO:
 type RequestIP struct {
 	Operation
-	XMLName xml.Name `xml:"requestIP"`
-	Message Messages `xml:"messages"`
+	XMLName xml.Name        `xml:"requestIP"`
+	Message Messages        `xml:"messages"`
+	Errs    OperationErrors `xml:"errors"`
 }
 
 // InvokeIP TODO:
 type InvokeIP struct {
 	Operation
-	XMLName xml.Name `xml:"invokeIP"`
-	Message Messages `xml:"messages"`
+	XMLName xml.Name        `xml:"invokeIP"`
+	Message Messages        `xml:"messages"`
+	Errs    OperationErrors `xml:"errors"`
 }
 
 // ProgressIP TODO:
 type ProgressIP struct {
 	Operation
-	XMLName xml.Name `xml:"progressIP"`
-	Message Messages `xml:"messages"`
+	XMLName xml.Name        `xml:"progressIP"`
+	Message Messages        `xml:"messages"`
+	Errs    OperationErrors `xml:"errors"`
 }
 
 // PubSubIP TODO:
 type PubSubIP struct {
 	Operation
-	XMLName xml.Name `xml:"pubsubIP"`
-	Message Messages `xml:"messages"`
+	XMLName xml.Name        `xml:"pubsubIP"`
+	Message Messages        `xml:"messages"`
+	Errs    OperationErrors `xml:"errors"`
 }
 
 // -------------------- Pattern messages --------------------
@@ -279,6 +402,8 @@ type InvokeMessage struct {
 // AckMessage TODO:
 type AckMessage struct {
 	XMLName xml.Name `xml:"acknowledgement"`
+	Comment string   `xml:"comment,attr"`
+	Types   []Type   `xml:"type"`
 }
 
 // ResponseMessage TODO:
@@ -0,0 +1,150 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2018 CNES
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package data
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Position is the position of an element in a specification file, the
+// line and the column of its start tag start at 1
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// IsValid checks if the position is known, the elements which are not
+// read with Decode have none
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+// String returns the position as file:line:col, the format understood
+// by the editors
+func (p Position) String() string {
+	if !p.IsValid() {
+		return p.File
+	}
+	return p.File + ":" + strconv.Itoa(p.Line) + ":" + strconv.Itoa(p.Column)
+}
+
+// UnmarshalXMLAttr reads the position added by Decode to the start tag
+// of an element
+func (p *Position) UnmarshalXMLAttr(attr xml.Attr) error {
+	column := strings.LastIndex(attr.Value, ":")
+	if column < 0 {
+		return fmt.Errorf("invalid position %q", attr.Value)
+	}
+	line := strings.LastIndex(attr.Value[:column], ":")
+	if line < 0 {
+		return fmt.Errorf("invalid position %q", attr.Value)
+	}
+	var err error
+	p.File = attr.Value[:line]
+	p.Line, err = strconv.Atoi(attr.Value[line+1 : column])
+	if err != nil {
+		return err
+	}
+	p.Column, err = strconv.Atoi(attr.Value[column+1:])
+	return err
+}
+
+// positionSpace is the namespace of the position attribute added to the
+// start tags, it is never taken for a prefix of the document as it
+// contains a ':'. The elements with a position read it in the field
+//
+//	Pos Position `xml:"urn:position pos,attr"`
+const positionSpace = "urn:position"
+
+// Lines finds the positions of the offsets of a file from the offsets
+// of the start of its lines
+type Lines struct {
+	file    string
+	offsets []int
+}
+
+// NewLines indexes the lines of the content of a file
+func NewLines(file string, content []byte) *Lines {
+	l := &Lines{file: file, offsets: []int{0}}
+	for i, c := range content {
+		if c == '\n' {
+			l.offsets = append(l.offsets, i+1)
+		}
+	}
+	return l
+}
+
+// Position returns the position of an offset in the file
+func (l *Lines) Position(offset int64) Position {
+	line := sort.Search(len(l.offsets), func(i int) bool { return int64(l.offsets[i]) > offset })
+	return Position{File: l.file, Line: line, Column: int(offset) - l.offsets[line-1] + 1}
+}
+
+// decoder reads a specification file for Decode, it adds the position
+// of each start tag to its attributes
+type decoder struct {
+	d     *xml.Decoder
+	lines *Lines
+}
+
+func newDecoder(file string, content []byte) *decoder {
+	return &decoder{d: xml.NewDecoder(bytes.NewReader(content)), lines: NewLines(file, content)}
+}
+
+// Token returns the next token of the file, the offset before a start
+// tag is the one of its '<'. The tokens are checked and their namespaces
+// are resolved by the decoder reading them.
+func (d *decoder) Token() (xml.Token, error) {
+	offset := d.d.InputOffset()
+	tok, err := d.d.RawToken()
+	start, ok := tok.(xml.StartElement)
+	if !ok {
+		return tok, err
+	}
+	pos := xml.Attr{Name: xml.Name{Space: positionSpace, Local: "pos"}, Value: d.lines.Position(offset).String()}
+	start.Attr = append(start.Attr[:len(start.Attr):len(start.Attr)], pos)
+	return start, err
+}
+
+// Decode reads the content of a specification file in v, like
+// xml.Unmarshal. The elements with a position record where they start
+// in file, and the errors are returned with their position.
+func Decode(file string, content []byte, v interface{}) error {
+	d := newDecoder(file, content)
+	err := xml.NewTokenDecoder(d).Decode(v)
+	if err != nil {
+		if syntax, ok := err.(*xml.SyntaxError); ok {
+			return fmt.Errorf("%s: %s", d.lines.Position(d.d.InputOffset()), syntax.Msg)
+		}
+		return fmt.Errorf("%s: %v", d.lines.Position(d.d.InputOffset()), err)
+	}
+	return nil
+}
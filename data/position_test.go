@@ -0,0 +1,129 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2018 CNES
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package data
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+func TestLinesPosition(t *testing.T) {
+	lines := NewLines("spec.xml", []byte("<a>\n  <b/>\n\n<c/>"))
+
+	tests := []struct {
+		offset int64
+		want   string
+	}{
+		{0, "spec.xml:1:1"},
+		{3, "spec.xml:1:4"},
+		{4, "spec.xml:2:1"},
+		{6, "spec.xml:2:3"},
+		{11, "spec.xml:3:1"},
+		{12, "spec.xml:4:1"},
+		{16, "spec.xml:4:5"},
+	}
+
+	for _, test := range tests {
+		if got := lines.Position(test.offset).String(); got != test.want {
+			t.Errorf("Position(%d) = %s, want %s", test.offset, got, test.want)
+		}
+	}
+}
+
+func TestPositionUnmarshalXMLAttr(t *testing.T) {
+	var p Position
+	err := p.UnmarshalXMLAttr(xml.Attr{Value: "C:/specs/spec.xml:12:5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p != (Position{File: "C:/specs/spec.xml", Line: 12, Column: 5}) {
+		t.Errorf("position = %+v", p)
+	}
+
+	for _, value := range []string{"spec.xml", "12", "spec.xml:x:5"} {
+		if err := p.UnmarshalXMLAttr(xml.Attr{Value: value}); err == nil {
+			t.Errorf("no error for %q", value)
+		}
+	}
+}
+
+func TestDecodePositions(t *testing.T) {
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<mal:specification xmlns:mal="http://www.ccsds.org/schema/ServiceSchema">
+  <mal:area name="TEST" number="100" version="1">
+    <mal:service name="First" number="1"/>
+	<mal:service name="Second" number="2"/>
+  </mal:area>
+</mal:specification>
+`
+	var query Query
+	err := Decode("spec.xml", []byte(content), &query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(query.AreaList) != 1 || len(query.AreaList[0].Services) != 2 {
+		t.Fatalf("decoded %+v", query)
+	}
+
+	area := query.AreaList[0]
+	if got := area.Pos.String(); got != "spec.xml:3:3" {
+		t.Errorf("position of the area = %s, want spec.xml:3:3", got)
+	}
+	// A tab counts as one column
+	for i, want := range []string{"spec.xml:4:5", "spec.xml:5:2"} {
+		if got := area.Services[i].Pos.String(); got != want {
+			t.Errorf("position of %s = %s, want %s", area.Services[i].Name, got, want)
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{
+			name:    "mismatched end tag",
+			content: "<specification>\n  <area>\n  </areas>\n</specification>\n",
+			err:     "spec.xml:3:11: element <area> closed by </areas>",
+		},
+		{
+			name:    "truncated",
+			content: "<specification>\n  <area>\n",
+			err:     "spec.xml:3:1: unexpected EOF",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var query Query
+			err := Decode("spec.xml", []byte(test.content), &query)
+			if err == nil || err.Error() != test.err {
+				t.Errorf("error = %v, want %s", err, test.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2018 CNES
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/etiennelndr/archiveservice_generator/src"
+)
+
+// runDiff compares two versions of a specification and prints the
+// differences. Each version can be made of several files, e.g. the MAL
+// and the COM, given as a comma separated list.
+func runDiff(args []string) int {
+	var oldFiles, newFiles stringList
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	flags.Var(&oldFiles, "old", "`file` of the old specification (can be repeated)")
+	flags.Var(&newFiles, "new", "`file` of the new specification (can be repeated)")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: generator diff [flags] [old.xml[,...] new.xml[,...]]\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+
+	err := flags.Parse(args)
+	if err == flag.ErrHelp {
+		return exitSuccess
+	} else if err != nil {
+		return exitUsage
+	}
+	if flags.NArg() == 2 {
+		oldFiles.Set(flags.Arg(0))
+		newFiles.Set(flags.Arg(1))
+	} else if flags.NArg() != 0 || len(oldFiles) == 0 || len(newFiles) == 0 {
+		fmt.Fprintln(os.Stderr, "generator: diff needs an old and a new specification")
+		flags.Usage()
+		return exitUsage
+	}
+
+	var oldGen, newGen = new(src.Generator), new(src.Generator)
+	err = load(oldGen, oldFiles, nil, nil, "")
+	if err != nil {
+		return fail(err)
+	}
+	err = load(newGen, newFiles, nil, nil, "")
+	if err != nil {
+		return fail(err)
+	}
+
+	changes := src.DiffSpecifications(oldGen.Areas, newGen.Areas)
+	for _, c := range changes {
+		fmt.Println(c)
+	}
+
+	if len(changes) != 0 {
+		return exitChanged
+	}
+	return exitSuccess
+}
@@ -0,0 +1,73 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2018 CNES
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package main
+
+import (
+	"github.com/etiennelndr/archiveservice_generator/src"
+)
+
+// runGenerate reads the specification and creates all the files of
+// the selected services
+func runGenerate(args []string) int {
+	var opts options
+	flags := newFlagSet("generate", &opts)
+	output := flags.String("output", src.DefaultOutputPath, "root `directory` of the generated files")
+	module := flags.String("module", src.DefaultModulePath, "Go import `path` of the output directory")
+	if code, ok := parseFlags(flags, args, &opts); !ok {
+		return code
+	}
+
+	// Variable for the generator
+	var g = &src.Generator{
+		OutputPath: *output,
+		ModulePath: *module,
+		Verbose:    opts.verbose,
+	}
+
+	err := load(g, opts.inputs, opts.areas, opts.services, opts.schema)
+	if err != nil {
+		return fail(err)
+	}
+
+	// Each area has its own package tree
+	for _, area := range g.Areas {
+		if g.Verbose {
+			printText(area)
+		}
+
+		err = g.InitDirectories(area)
+		if err != nil {
+			return fail(err)
+		}
+
+		// Create information in files
+		err = g.CreateInformation(area)
+		if err != nil {
+			return fail(err)
+		}
+	}
+
+	return exitSuccess
+}
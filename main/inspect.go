@@ -0,0 +1,119 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2018 CNES
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/etiennelndr/archiveservice_generator/src"
+)
+
+// runInspect prints the services of the specification with their
+// operations and data types
+func runInspect(args []string) int {
+	var opts options
+	flags := newFlagSet("inspect", &opts)
+	format := flags.String("format", "text", "output `format`: text or json")
+	if code, ok := parseFlags(flags, args, &opts); !ok {
+		return code
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "generator: unknown format %s\n", *format)
+		return exitUsage
+	}
+
+	var g = &src.Generator{Verbose: opts.verbose}
+	err := load(g, opts.inputs, opts.areas, opts.services, opts.schema)
+	if err != nil {
+		return fail(err)
+	}
+
+	if *format == "json" {
+		b, err := json.MarshalIndent(g.Areas, "", "  ")
+		if err != nil {
+			return fail(err)
+		}
+		fmt.Println(string(b))
+		return exitSuccess
+	}
+
+	for _, area := range g.Areas {
+		printText(area)
+	}
+	return exitSuccess
+}
+
+// printText prints the services of an area with their operations
+// and data types
+func printText(area src.Area) {
+	fmt.Println("AREA " + area.Name + " (number " + area.Number + ", version " + area.Version + ")")
+	for _, service := range area.Services {
+		fmt.Println(strings.ToUpper(service.Name))
+		fmt.Println("Operations:")
+		for _, op := range service.Operations {
+			fmt.Println(op.Name + " (" + op.Pattern.Name + ")")
+		}
+		fmt.Println("Datas:")
+		for _, comp := range service.Composites {
+			fmt.Println("Composite: " + comp.Name)
+		}
+		for _, enum := range service.Enumerations {
+			fmt.Println("Enumeration: " + enum.Name)
+		}
+		if len(service.Objects)+len(service.Events) != 0 {
+			fmt.Println("COM:")
+			for _, o := range service.Objects {
+				fmt.Println("Object: " + o.Name + " (" + o.Number + ")")
+			}
+			for _, e := range service.Events {
+				fmt.Println("Event: " + e.Name + " (" + e.Number + ")")
+			}
+		}
+	}
+	if len(area.Fundamentals)+len(area.Attributes)+len(area.Composites)+len(area.Enumerations) != 0 {
+		fmt.Println("Area datas:")
+		for _, f := range area.Fundamentals {
+			fmt.Println("Fundamental: " + f.Name)
+		}
+		for _, at := range area.Attributes {
+			fmt.Println("Attribute: " + at.Name)
+		}
+		for _, comp := range area.Composites {
+			fmt.Println("Composite: " + comp.Name)
+		}
+		for _, enum := range area.Enumerations {
+			fmt.Println("Enumeration: " + enum.Name)
+		}
+	}
+	if len(area.Errors) != 0 {
+		fmt.Println("Errors:")
+		for _, e := range area.Errors {
+			fmt.Println(e.Name + " (" + e.Number + ")")
+		}
+	}
+}
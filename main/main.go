@@ -26,55 +26,204 @@ package main
 
 import (
 	"errors"
+	"flag"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/etiennelndr/archiveservice_generator/src"
 )
 
-func main() {
-	fmt.Println("MAL API - Service Generator")
+// Exit codes of the generator
+const (
+	exitSuccess = 0
+	exitFailure = 1
+	exitUsage   = 2
+	// The specifications compared by diff are different
+	exitChanged = 3
+)
 
-	// Variable for the generator
-	var g = new(src.Generator)
+// command is a subcommand of the generator
+type command struct {
+	name        string
+	description string
+	run         func(args []string) int
+}
 
-	// Open and read the xml file
-	err := g.OpenAndReadXML("../archiveservice_generator/XML/ServiceDefCOM.xml")
-	if err != nil {
-		panic(err)
+var commands = []command{
+	{"generate", "generate the Go API of the services", runGenerate},
+	{"validate", "check the specification without generating anything", runValidate},
+	{"inspect", "print the services, operations and data types", runInspect},
+	{"diff", "compare two versions of a specification", runDiff},
+}
+
+// stringList is a flag which can be given several times
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v != "" {
+			*l = append(*l, v)
+		}
 	}
+	return nil
+}
 
-	// Now we can retrieve the datas
-	g.RetrieveInformation()
+// options holds the flags shared by all the commands reading a
+// specification
+type options struct {
+	inputs   stringList
+	areas    stringList
+	services stringList
+	schema   string
+	verbose  bool
+}
 
-	if g.GenArea.Name == "" {
-		panic(errors.New("Can't retrieve the Area"))
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run finds the command to execute and returns the exit code of the
+// program
+func run(args []string) int {
+	if len(args) == 0 {
+		usage()
+		return exitUsage
 	}
 
-	//fmt.Println(g.GenArea.Services)
-	for _, service := range g.GenArea.Services {
-		fmt.Println(strings.ToUpper(service.Name))
-		fmt.Println("Operations:")
-		for _, op := range service.Operations {
-			fmt.Println(op.Name)
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:])
 		}
-		fmt.Println("Datas:")
-		for _, comp := range service.Composites {
-			fmt.Println("Composite: " + comp.Name)
+	}
+
+	if args[0] == "help" || args[0] == "-h" || args[0] == "-help" {
+		usage()
+		return exitSuccess
+	}
+
+	fmt.Fprintf(os.Stderr, "generator: unknown command %s\n", args[0])
+	usage()
+	return exitUsage
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: generator <command> [flags] [file ...]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.description)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun 'generator <command> -h' for the flags of a command.\n")
+}
+
+// newFlagSet creates the flags of a command, opts receives the flags
+// shared by all the commands
+func newFlagSet(name string, opts *options) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Var(&opts.inputs, "input", "specification `file` to read (can be repeated)")
+	flags.Var(&opts.areas, "area", "only keep this `area` (can be repeated)")
+	flags.Var(&opts.services, "service", "only keep this `service` (can be repeated)")
+	flags.StringVar(&opts.schema, "schema", "", "check the files with the XML schemas of this `directory` first")
+	flags.BoolVar(&opts.verbose, "v", false, "print progress messages")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: generator %s [flags] [file ...]\n\nFlags:\n", name)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parseFlags parses the arguments of a command, the remaining arguments
+// are added to the input files. If ok is false the command must stop
+// and return code.
+func parseFlags(flags *flag.FlagSet, args []string, opts *options) (code int, ok bool) {
+	err := flags.Parse(args)
+	if err == flag.ErrHelp {
+		return exitSuccess, false
+	} else if err != nil {
+		return exitUsage, false
+	}
+
+	opts.inputs = append(opts.inputs, flags.Args()...)
+	if len(opts.inputs) == 0 {
+		fmt.Fprintln(os.Stderr, "generator: no specification file given")
+		flags.Usage()
+		return exitUsage, false
+	}
+
+	return exitSuccess, true
+}
+
+// load reads the specification files and builds the model of the
+// selected areas and services. The files are checked with the XML
+// schemas of the schema directory first, if it is given.
+func load(g *src.Generator, inputs []string, areas []string, services []string, schema string) error {
+	if schema != "" {
+		err := checkSchema(schema, inputs)
+		if err != nil {
+			return err
 		}
-		for _, enum := range service.Enumerations {
-			fmt.Println("Enumeration: " + enum.Name)
+	}
+
+	// Open and read the xml files
+	for _, input := range inputs {
+		err := g.OpenAndReadXML(input)
+		if err != nil {
+			return err
 		}
 	}
 
-	err = g.InitDirectories()
+	// Now we can retrieve the datas
+	g.RetrieveInformation()
+
+	if len(g.Areas) == 0 {
+		return errors.New("can't retrieve any Area")
+	}
+
+	// Every type must be defined in one of the files and the model must
+	// be valid to be generated
+	err := g.Validate()
 	if err != nil {
-		panic(err)
+		return err
 	}
 
-	// Create information in files
-	err = g.CreateInformation()
+	err = g.FilterAreas(areas)
 	if err != nil {
-		panic(err)
+		return err
 	}
+
+	return g.FilterServices(services)
+}
+
+// checkSchema checks the specification files with the schemas of the
+// MAL and the COM in dir, all the violations are reported with their
+// position
+func checkSchema(dir string, inputs []string) error {
+	schema, err := src.LoadSchema(filepath.Join(dir, src.ServiceSchemaFile), filepath.Join(dir, src.COMSchemaFile))
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	for _, input := range inputs {
+		p, err := schema.ValidateFile(input)
+		if err != nil {
+			return err
+		}
+		problems = append(problems, p...)
+	}
+
+	if len(problems) != 0 {
+		return errors.New("specification does not match the schema:\n\t" + strings.Join(problems, "\n\t"))
+	}
+	return nil
+}
+
+// fail prints an error and returns the failure exit code
+func fail(err error) int {
+	fmt.Fprintln(os.Stderr, "generator: "+err.Error())
+	return exitFailure
 }
@@ -0,0 +1,123 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2018 CNES
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Specifications bundled with the generator
+const (
+	malSpecification = "../XML/ServiceDefMAL.xml"
+	comSpecification = "../XML/ServiceDefCOM.xml"
+)
+
+// quiet discards what the commands print during a test
+func quiet(t *testing.T) {
+	t.Helper()
+	null, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = null, null
+	t.Cleanup(func() {
+		os.Stdout, os.Stderr = stdout, stderr
+		null.Close()
+	})
+}
+
+func TestGenerateFlags(t *testing.T) {
+	quiet(t)
+	output := t.TempDir()
+
+	code := run([]string{"generate", "-output", output, "-module", "example.com/api", "-input", malSpecification, comSpecification})
+	if code != exitSuccess {
+		t.Fatalf("exit code = %d, want %d", code, exitSuccess)
+	}
+	content, err := os.ReadFile(filepath.Join(output, "com/archiveservice/archive/consumer/consumer.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), `"example.com/api/com/archiveservice/data"`) {
+		t.Error("the consumer does not import its data package from the module")
+	}
+}
+
+func TestGenerateArea(t *testing.T) {
+	quiet(t)
+	output := t.TempDir()
+
+	code := run([]string{"generate", "-output", output, "-area", "COM", malSpecification, comSpecification})
+	if code != exitSuccess {
+		t.Fatalf("exit code = %d, want %d", code, exitSuccess)
+	}
+	if _, err := os.Stat(filepath.Join(output, "com")); err != nil {
+		t.Error(err)
+	}
+	if _, err := os.Stat(filepath.Join(output, "mal")); !os.IsNotExist(err) {
+		t.Errorf("the MAL is generated with -area COM")
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	quiet(t)
+	missing := filepath.Join(t.TempDir(), "missing.xml")
+
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"no command", nil, exitUsage},
+		{"unknown command", []string{"build"}, exitUsage},
+		{"help", []string{"help"}, exitSuccess},
+		{"help of a command", []string{"generate", "-h"}, exitSuccess},
+		{"unknown flag", []string{"validate", "-unknown", malSpecification}, exitUsage},
+		{"no file", []string{"validate"}, exitUsage},
+		{"missing file", []string{"validate", missing}, exitFailure},
+		{"valid", []string{"validate", malSpecification, comSpecification}, exitSuccess},
+		{"unresolved types", []string{"validate", comSpecification}, exitFailure},
+		{"unknown area", []string{"validate", "-area", "SM", malSpecification, comSpecification}, exitFailure},
+		{"schema", []string{"validate", "-schema", "../XML", malSpecification, comSpecification}, exitSuccess},
+		{"inspect", []string{"inspect", "-format", "json", malSpecification, comSpecification}, exitSuccess},
+		{"unknown format", []string{"inspect", "-format", "yaml", malSpecification}, exitUsage},
+		{"diff without change", []string{"diff", malSpecification + "," + comSpecification, malSpecification + "," + comSpecification}, exitSuccess},
+		{"diff with changes", []string{"diff", malSpecification, malSpecification + "," + comSpecification}, exitChanged},
+		{"diff of one specification", []string{"diff", malSpecification}, exitUsage},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if code := run(test.args); code != test.code {
+				t.Errorf("exit code = %d, want %d", code, test.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2018 CNES
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/etiennelndr/archiveservice_generator/src"
+)
+
+// runValidate reads the specification and checks it without writing
+// any file
+func runValidate(args []string) int {
+	var opts options
+	flags := newFlagSet("validate", &opts)
+	if code, ok := parseFlags(flags, args, &opts); !ok {
+		return code
+	}
+
+	var g = &src.Generator{Verbose: opts.verbose}
+	err := load(g, opts.inputs, opts.areas, opts.services, opts.schema)
+	if err != nil {
+		return fail(err)
+	}
+
+	if g.Verbose {
+		for _, area := range g.Areas {
+			fmt.Printf("%s: %d service(s) OK\n", area.Name, len(area.Services))
+		}
+	}
+
+	return exitSuccess
+}
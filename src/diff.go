@@ -0,0 +1,270 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2018 CNES
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package src
+
+import (
+	"strings"
+)
+
+// Kinds of change between two versions of a specification
+const (
+	ChangeAdded    = "added"
+	ChangeRemoved  = "removed"
+	ChangeModified = "changed"
+)
+
+// Change is a difference between two versions of a specification
+type Change struct {
+	Kind   string
+	Path   string
+	Detail string
+}
+
+// String returns the change as a line of a diff
+func (c Change) String() string {
+	switch c.Kind {
+	case ChangeAdded:
+		return "+ " + c.Path
+	case ChangeRemoved:
+		return "- " + c.Path
+	}
+	return "~ " + c.Path + ": " + c.Detail
+}
+
+// entry is a named element of the specification with a signature,
+// two entries with the same name but different signatures have changed
+type entry struct {
+	name      string
+	signature string
+}
+
+// DiffSpecifications compares two versions of a set of areas and
+// returns the changes
+func DiffSpecifications(olds []Area, news []Area) []Change {
+	var changes []Change
+	for _, o := range olds {
+		found := false
+		for _, n := range news {
+			if o.Name == n.Name {
+				changes = append(changes, DiffAreas(o, n)...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			changes = append(changes, Change{ChangeRemoved, o.Name, ""})
+		}
+	}
+	for _, n := range news {
+		found := false
+		for _, o := range olds {
+			if o.Name == n.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			changes = append(changes, Change{ChangeAdded, n.Name, ""})
+		}
+	}
+	return changes
+}
+
+// DiffAreas compares two versions of an area and returns the changes,
+// the old elements are listed first and the added ones last
+func DiffAreas(oldArea Area, newArea Area) []Change {
+	var changes []Change
+	path := newArea.Name
+
+	if oldArea.Name != newArea.Name {
+		changes = append(changes, Change{ChangeModified, path, "name " + oldArea.Name + " -> " + newArea.Name})
+	}
+	if oldArea.Number != newArea.Number {
+		changes = append(changes, Change{ChangeModified, path, "number " + oldArea.Number + " -> " + newArea.Number})
+	}
+	if oldArea.Version != newArea.Version {
+		changes = append(changes, Change{ChangeModified, path, "version " + oldArea.Version + " -> " + newArea.Version})
+	}
+
+	changes = append(changes, diffEntries(path, attributeEntries(oldArea.Attributes), attributeEntries(newArea.Attributes))...)
+	changes = append(changes, diffEntries(path, compositeEntries(oldArea.Composites), compositeEntries(newArea.Composites))...)
+	changes = append(changes, diffEntries(path, enumerationEntries(oldArea.Enumerations), enumerationEntries(newArea.Enumerations))...)
+	changes = append(changes, diffEntries(path, errorEntries(oldArea.Errors), errorEntries(newArea.Errors))...)
+
+	// Services
+	var oldServices, newServices []entry
+	for _, s := range oldArea.Services {
+		oldServices = append(oldServices, entry{s.Name, "number " + s.Number})
+	}
+	for _, s := range newArea.Services {
+		newServices = append(newServices, entry{s.Name, "number " + s.Number})
+	}
+	changes = append(changes, diffEntries(path, oldServices, newServices)...)
+
+	for _, o := range oldArea.Services {
+		for _, n := range newArea.Services {
+			if o.Name == n.Name {
+				changes = append(changes, diffServices(path+"::"+n.Name, o, n)...)
+			}
+		}
+	}
+
+	return changes
+}
+
+// diffServices compares the operations and the data types of two
+// versions of a service
+func diffServices(path string, oldService Service, newService Service) []Change {
+	var changes []Change
+	changes = append(changes, diffEntries(path, operationEntries(oldService.Operations), operationEntries(newService.Operations))...)
+	changes = append(changes, diffEntries(path, compositeEntries(oldService.Composites), compositeEntries(newService.Composites))...)
+	changes = append(changes, diffEntries(path, enumerationEntries(oldService.Enumerations), enumerationEntries(newService.Enumerations))...)
+	changes = append(changes, diffEntries(path, errorEntries(oldService.Errors), errorEntries(newService.Errors))...)
+	changes = append(changes, diffEntries(path, objectEntries(oldService.Objects), objectEntries(newService.Objects))...)
+	changes = append(changes, diffEntries(path, objectEntries(oldService.Events), objectEntries(newService.Events))...)
+	return changes
+}
+
+// diffEntries compares two lists of entries by name
+func diffEntries(path string, olds []entry, news []entry) []Change {
+	var changes []Change
+	for _, o := range olds {
+		found := false
+		for _, n := range news {
+			if o.name == n.name {
+				found = true
+				if o.signature != n.signature {
+					changes = append(changes, Change{ChangeModified, path + "::" + n.name, o.signature + " -> " + n.signature})
+				}
+				break
+			}
+		}
+		if !found {
+			changes = append(changes, Change{ChangeRemoved, path + "::" + o.name, ""})
+		}
+	}
+	for _, n := range news {
+		found := false
+		for _, o := range olds {
+			if o.name == n.name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			changes = append(changes, Change{ChangeAdded, path + "::" + n.name, ""})
+		}
+	}
+	return changes
+}
+
+func operationEntries(operations []Operation) []entry {
+	var entries []entry
+	for _, op := range operations {
+		var messages []string
+		for _, m := range op.Pattern.Messages {
+			messages = append(messages, m.Name+"("+typesSignature(m.Types)+")")
+		}
+		if len(op.Errors) != 0 {
+			var errors []string
+			for _, e := range op.Errors {
+				errors = append(errors, e.String())
+			}
+			messages = append(messages, "errors("+strings.Join(errors, ", ")+")")
+		}
+		entries = append(entries, entry{op.Name, op.Pattern.Name + " " + op.Number + " " + strings.Join(messages, " ")})
+	}
+	return entries
+}
+
+func attributeEntries(attributes []Attribute) []entry {
+	var entries []entry
+	for _, a := range attributes {
+		entries = append(entries, entry{a.Name, "attribute " + a.ShortFormPart})
+	}
+	return entries
+}
+
+func compositeEntries(composites []Composite) []entry {
+	var entries []entry
+	for _, c := range composites {
+		var fields []string
+		for _, f := range c.Fields {
+			field := f.Name + " " + f.Type().String()
+			if !f.IsNullable() {
+				field += " not null"
+			}
+			fields = append(fields, field)
+		}
+		signature := "composite " + c.ShortFormPart + " extends " + c.AreaOfTypeToExtend + "::" + c.NameOfTypeToExtend +
+			" {" + strings.Join(fields, ", ") + "}"
+		entries = append(entries, entry{c.Name, signature})
+	}
+	return entries
+}
+
+func enumerationEntries(enumerations []Enumeration) []entry {
+	var entries []entry
+	for _, e := range enumerations {
+		var items []string
+		for _, i := range e.Items {
+			items = append(items, i.Value+"="+i.NValue)
+		}
+		entries = append(entries, entry{e.Name, "enumeration " + e.ShortFormPart + " {" + strings.Join(items, ", ") + "}"})
+	}
+	return entries
+}
+
+func objectEntries(objects []COMObject) []entry {
+	var entries []entry
+	for _, o := range objects {
+		kind := "object "
+		if o.Event {
+			kind = "event "
+		}
+		signature := kind + o.Number
+		if o.HasBody() {
+			signature += " body " + o.ObjectType.String()
+		}
+		entries = append(entries, entry{o.Name, signature})
+	}
+	return entries
+}
+
+func errorEntries(errors []Error) []entry {
+	var entries []entry
+	for _, e := range errors {
+		entries = append(entries, entry{e.Name, "error " + e.Number})
+	}
+	return entries
+}
+
+func typesSignature(types []Type) string {
+	var names []string
+	for _, t := range types {
+		names = append(names, t.String())
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,105 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2018 CNES
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+
+package src
+
+import "testing"
+
+func TestDiffSpecifications(t *testing.T) {
+	field := func(canBeNull string) string {
+		return `<mal:composite name="Sample" shortFormPart="2">
+          <mal:extends>
+            <mal:type name="Composite" area="MAL"/>
+          </mal:extends>
+          <mal:field name="id" canBeNull="` + canBeNull + `">
+            <mal:type name="Long" area="MAL"/>
+          </mal:field>
+        </mal:composite>
+`
+	}
+
+	tests := []struct {
+		name         string
+		oldOps       string
+		oldDataTypes string
+		newOps       string
+		newDataTypes string
+		changes      []string
+	}{
+		{
+			name:         "identical",
+			oldOps:       sendOperation("pong", "2"),
+			oldDataTypes: field("true"),
+			newOps:       sendOperation("pong", "2"),
+			newDataTypes: field("true"),
+		},
+		{
+			name:    "operation added",
+			newOps:  sendOperation("pong", "2"),
+			changes: []string{"+ TEST::Test::pong"},
+		},
+		{
+			name:         "composite removed",
+			oldDataTypes: composite("First", "2", "Composite"),
+			changes:      []string{"- TEST::Test::First"},
+		},
+		{
+			name:    "operation number",
+			oldOps:  sendOperation("pong", "2"),
+			newOps:  sendOperation("pong", "3"),
+			changes: []string{"~ TEST::Test::pong: send 2 send() -> send 3 send()"},
+		},
+		{
+			name:         "nullability of a field",
+			oldDataTypes: field("true"),
+			newDataTypes: field("false"),
+			changes: []string{"~ TEST::Test::Sample: composite 2 extends MAL::Composite {id MAL::Long} -> " +
+				"composite 2 extends MAL::Composite {id MAL::Long not null}"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			oldGen, err := loadTestArea(t, test.oldOps, test.oldDataTypes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			newGen, err := loadTestArea(t, test.newOps, test.newDataTypes)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			changes := DiffSpecifications(oldGen.Areas, newGen.Areas)
+			if len(changes) != len(test.changes) {
+				t.Fatalf("changes = %v, want %v", changes, test.changes)
+			}
+			for i, c := range changes {
+				if c.String() != test.changes[i] {
+					t.Errorf("change = %s, want %s", c, test.changes[i])
+				}
+			}
+		})
+	}
+}
@@ -26,25 +26,45 @@ package src
 
 import (
 	"bytes"
-	"encoding/xml"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/etiennelndr/archiveservice_generator/data"
 	"github.com/etiennelndr/archiveservice_generator/utils"
 )
 
+// Defaults of Generator.OutputPath and Generator.ModulePath
+const (
+	DefaultOutputPath = "../tests/"
+	DefaultModulePath = "github.com/etiennelndr/tests"
+)
+
 // Generator TODO:
 type Generator struct {
-	buffer  *bytes.Buffer
-	xmlRaw  data.Query
-	GenArea Area
+	buffer *bytes.Buffer
+	xmlRaw data.Query
+	types  map[string]TypeDefinition
+	Areas  []Area
+	// all the areas read, even the ones removed by the filters
+	specification []Area
+	// problems found while building the model, reported by Validate
+	problems []string
+
+	// OutputPath is the root directory of the generated files
+	OutputPath string
+	// ModulePath is the Go import path corresponding to OutputPath
+	ModulePath string
+	// Verbose enables the progress messages
+	Verbose bool
 }
 
-// OpenAndReadXML TODO:
+// OpenAndReadXML reads a specification file and adds its areas to the
+// ones already read, it can be called once per file of the specification
+// (e.g. MAL, COM and the mission areas)
 func (g *Generator) OpenAndReadXML(path string) error {
 	absPath, _ := filepath.Abs(path)
 	xmlFile, err := os.Open(absPath)
@@ -58,17 +78,119 @@ func (g *Generator) OpenAndReadXML(path string) error {
 		return err
 	}
 
-	err = xml.Unmarshal(b, &g.xmlRaw)
+	var query data.Query
+	err = data.Decode(path, b, &query)
 	if err != nil {
 		return err
 	}
 
+	// The areas of all the files are merged in the same specification
+	for _, area := range query.AreaList {
+		for _, a := range g.xmlRaw.AreaList {
+			if a.Name == area.Name {
+				return fmt.Errorf("%s: area %s is already defined at %s", area.Pos, area.Name, a.Pos)
+			}
+		}
+		g.xmlRaw.AreaList = append(g.xmlRaw.AreaList, area)
+	}
+
+	return nil
+}
+
+// FilterAreas only keeps the areas whose names are given. An error is
+// returned if one of these names is not a known area.
+func (g *Generator) FilterAreas(names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	g.keepSpecification()
+
+	var areas []Area
+	for _, name := range names {
+		found := false
+		for _, a := range g.Areas {
+			if strings.EqualFold(a.Name, name) {
+				areas = append(areas, a)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown area %s", name)
+		}
+	}
+	g.Areas = areas
+
+	return nil
+}
+
+// FilterServices only keeps the services whose names are given. An
+// error is returned if one of these names is not a service of the
+// areas.
+func (g *Generator) FilterServices(names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	g.keepSpecification()
+
+	for _, name := range names {
+		found := false
+		for _, a := range g.Areas {
+			for _, s := range a.Services {
+				if strings.EqualFold(s.Name, name) {
+					found = true
+				}
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown service %s", name)
+		}
+	}
+
+	for i := range g.Areas {
+		var services []Service
+		for _, s := range g.Areas[i].Services {
+			for _, name := range names {
+				if strings.EqualFold(s.Name, name) {
+					services = append(services, s)
+					break
+				}
+			}
+		}
+		g.Areas[i].Services = services
+	}
+
 	return nil
 }
 
-// InitDirectories create directories and files for each service
-func (g *Generator) InitDirectories() error {
-	filepath, err := testPath()
+// keepSpecification saves all the areas before they are filtered, the
+// types of the removed areas and services can still be referenced
+func (g *Generator) keepSpecification() {
+	if g.specification == nil {
+		g.specification = append([]Area(nil), g.Areas...)
+	}
+}
+
+// allAreas returns all the areas read, even the ones removed by the
+// filters
+func (g *Generator) allAreas() []Area {
+	if g.specification != nil {
+		return g.specification
+	}
+	return g.Areas
+}
+
+// logf prints a progress message when the generator is verbose
+func (g *Generator) logf(format string, args ...interface{}) {
+	if g.Verbose {
+		fmt.Printf(format+"\n", args...)
+	}
+}
+
+// InitDirectories create directories and files for each service of
+// an area
+func (g *Generator) InitDirectories(area Area) error {
+	filepath, err := g.areaPath(area)
 	if err != nil {
 		return err
 	}
@@ -78,39 +200,55 @@ func (g *Generator) InitDirectories() error {
 		return err
 	}
 
-	for _, service := range g.GenArea.Services {
-		// nameservice
-		serviceabspath := filepath + "/" + strings.ToLower(service.Name) + "service/"
-		err = os.MkdirAll(serviceabspath, os.ModePerm)
+	// data of the area, the MAL data types are the ones of the MAL API
+	if area.Name != malArea {
+		err = os.MkdirAll(filepath+"/data/", os.ModePerm)
 		if err != nil {
 			return err
 		}
-
-		// name
-		name := serviceabspath + strings.ToLower(service.Name) + "/"
-		err = os.MkdirAll(name, os.ModePerm)
+		f, err := os.Create(filepath + "/data/data.go")
 		if err != nil {
 			return err
 		}
+		utils.WriteHeader(f, "data")
+		f.Close()
+	}
 
-		// service
-		err = os.MkdirAll(name+"service/", os.ModePerm)
+	// errors of the area
+	if len(area.Errors) != 0 {
+		err = os.MkdirAll(filepath+"/errors/", os.ModePerm)
 		if err != nil {
 			return err
 		}
-		f, err := os.Create(name + "service/service.go")
+		f, err := os.Create(filepath + "/errors/errors.go")
 		if err != nil {
 			return err
 		}
-		utils.WriteHeader(f, "service")
+		utils.WriteHeader(f, "errors")
 		f.Close()
+	}
+
+	for _, service := range area.Services {
+		// nameservice
+		serviceabspath := filepath + "/" + strings.ToLower(service.Name) + "service/"
+		err = os.MkdirAll(serviceabspath, os.ModePerm)
+		if err != nil {
+			return err
+		}
+
+		// name
+		name := serviceabspath + strings.ToLower(service.Name) + "/"
+		err = os.MkdirAll(name, os.ModePerm)
+		if err != nil {
+			return err
+		}
 
 		// consumer
 		err = os.MkdirAll(name+"consumer/", os.ModePerm)
 		if err != nil {
 			return err
 		}
-		f, err = os.Create(name + "consumer/consumer.go")
+		f, err := os.Create(name + "consumer/consumer.go")
 		if err != nil {
 			return err
 		}
@@ -176,286 +314,164 @@ func (g *Generator) InitDirectories() error {
 		}
 		utils.WriteHeader(f, "tests")
 		f.Close()
+
+		// objects, only for the services with COM objects or events
+		if len(service.Objects)+len(service.Events) != 0 {
+			err = os.MkdirAll(name+"objects/", os.ModePerm)
+			if err != nil {
+				return err
+			}
+			f, err = os.Create(name + "objects/objects.go")
+			if err != nil {
+				return err
+			}
+			utils.WriteHeader(f, "objects")
+			f.Close()
+		}
 	}
 
 	return nil
 }
 
-// CreateInformation TODO:
-func (g *Generator) CreateInformation() error {
-	err := g.createConstants()
+// CreateInformation writes the files of an area, its directories must
+// have been created by InitDirectories
+func (g *Generator) CreateInformation(area Area) error {
+	err := g.createConstants(area)
+	if err != nil {
+		return err
+	}
 
-	err = g.createService()
+	err = g.createObjects(area)
 	if err != nil {
 		return err
 	}
 
-	err = g.createData()
+	err = g.createData(area)
 	if err != nil {
 		return err
 	}
 
-	err = g.createErrors()
+	err = g.createErrors(area)
 	if err != nil {
 		return err
 	}
 
-	err = g.createProvider()
+	err = g.createProvider(area)
 	if err != nil {
 		return err
 	}
 
-	return g.createConsumer()
+	return g.createConsumer(area)
 }
 
-func (g *Generator) createConstants() error {
-	filepath, err := testPath()
+func (g *Generator) createConstants(area Area) error {
+	filepath, err := g.areaPath(area)
 	if err != nil {
 		return err
 	}
 
-	for _, s := range g.GenArea.Services {
+	for _, s := range area.Services {
 		var buffer = new(bytes.Buffer)
 		serviceNameToLower := strings.ToLower(s.Name)
 		constantsfile := filepath + "/" + serviceNameToLower + "service/" + serviceNameToLower + "/constants/constants.go"
 
-		file, err := os.OpenFile(constantsfile, os.O_APPEND|os.O_WRONLY, os.ModeAppend)
-		if err != nil {
-			return err
-		}
-		defer file.Close()
-
 		buffer.WriteString("\n// Constants for the " + s.Name + " Service\n")
 		buffer.WriteString("const (\n")
 		buffer.WriteString("\t" + serviceIdentifier(s) + " = \"" + s.Name + "\"\n")
-		buffer.WriteString("\t" + serviceNumber(s) + "     = " + s.Number + "\n")
+		buffer.WriteString("\t" + serviceNumber(s) + "     = " + strconv.Itoa(int(s.number)) + "\n")
 		buffer.WriteString(")\n")
 		buffer.WriteString("\nconst (\n")
-		buffer.WriteString("\t" + areaIdentifier(s) + " = \"" + g.GenArea.Name + "\"\n")
+		buffer.WriteString("\t" + areaIdentifier(s) + " = \"" + area.Name + "\"\n")
+		buffer.WriteString("\t" + areaNumber(s) + "     = " + strconv.Itoa(int(area.number)) + "\n")
+		buffer.WriteString("\t" + areaVersion(s) + "    = " + strconv.Itoa(int(area.version)) + "\n")
 		buffer.WriteString(")\n")
 		if len(s.Operations) != 0 {
 			buffer.WriteString("\n// Constants for the operations\n")
 			buffer.WriteString("const (\n")
+			width := 0
 			for _, op := range s.Operations {
-				buffer.WriteString("\tOPERATION_IDENTIFIER_" + strings.ToUpper(op.Name) + " = " + op.Number + "\n")
-			}
-			buffer.WriteString(")\n")
-		}
-
-		_, err = file.Write(buffer.Bytes())
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
-func serviceImports(buf *bytes.Buffer, s Service) {
-	sName := strings.ToLower(s.Name)
-	buf.WriteString("\nimport (\n")
-	buf.WriteString("\t\"github.com/ccsdsmo/malgo/mal\"\n") // FIXME: it might not be generic
-	buf.WriteString("\t\"github.com/ccsdsmo/malgo/com\"\n") // FIXME: same as mal import
-	buf.WriteString("\tcnst \"github.com/etiennelndr/tests/" + sName + "service/" + sName + "/constants\"")
-	buf.WriteString("\n\t\"sync\"\n")
-	buf.WriteString(")\n")
-}
-
-func serviceStructure(buf *bytes.Buffer, serviceName string) {
-	buf.WriteString("\ntype " + serviceName + "Service struct {\n")
-	buf.WriteString("\tAreaIdentifier 	 mal.Identifier\n")
-	buf.WriteString("\tServiceIdentifier mal.Identifier\n")
-	buf.WriteString("\tAreaNumber 		 mal.UShort\n")
-	buf.WriteString("\tServiceNumber 	 mal.Integer\n")
-	buf.WriteString("\tAreaVersion 		 mal.UOctet\n\n")
-	buf.WriteString("\trunning 			 bool\n")
-	buf.WriteString("\twg 				 sync.WaitGroup\n")
-	buf.WriteString("}\n")
-}
-
-func serviceCreateService(buf *bytes.Buffer, s Service, area Area) {
-	buf.WriteString("\nfunc New" + s.Name + "Service() *" + s.Name + "Service {\n")
-	buf.WriteString("\t" + strings.ToLower(s.Name) + "Service := &" + s.Name + "Service{\n")
-	buf.WriteString("\t\tAreaIdentifier: cnst." + areaIdentifier(s) + ",\n")
-	buf.WriteString("\t\tServiceIdentifier: cnst." + serviceIdentifier(s) + ",\n")
-	buf.WriteString("\t\tAreaNumber: com." + strings.ToUpper(area.Name) + "_AREA_NUMBER,\n")
-	buf.WriteString("\t\tServiceNumber: cnst." + serviceNumber(s) + ",\n")
-	buf.WriteString("\t\tAreaVersion: com." + strings.ToUpper(area.Name) + "_AREA_VERSION,\n")
-	buf.WriteString("\t\trunning: true,\n")
-	buf.WriteString("\t\twg: *new(sync.WaitGroup),\n")
-	buf.WriteString("\t}\n")
-	buf.WriteString("\treturn " + strings.ToLower(s.Name) + "Service\n")
-	buf.WriteString("}\n")
-}
-
-func serviceOperations(buf *bytes.Buffer, s Service, a Area) {
-	for _, op := range s.Operations {
-		buf.WriteString("\n")
-		// Print the comment of the operation
-		printComment(buf, op.Name+": "+op.Comment)
-
-		// Now print the header and some lines
-		buf.WriteString("func (s *" + s.Name + "Service) " + charsToUpper(op.Name, 0) + " (consumerURL string, providerURL string,")
-		for i, t := range op.Pattern.Messages[0].Types {
-			buf.WriteString(" " + charsToLower(t.AdaptType(), 0) + " " + strings.ToLower(t.Area) + "." + t.AdaptType())
-			if i+1 < len(op.Pattern.Messages[0].Types) {
-				buf.WriteString(",")
-			}
-		}
-		buf.WriteString(") (")
-		// Types to return
-		for _, t := range op.Pattern.Messages[len(op.Pattern.Messages)-1].Types {
-			lowercaseName := strings.ToLower(t.Name)
-			// If this element is not abstract it must be a pointer
-			if lowercaseName != "element" && lowercaseName != "attribute" && lowercaseName != "composite" &&
-				!a.IsAbstractInArea(t.Name) && !s.IsAbstractInService(t.Name) {
-				buf.WriteString("*")
-			}
-			buf.WriteString(strings.ToLower(t.Area) + "." + charsToUpper(t.Name, 0) + ", ")
-		}
-		buf.WriteString("error) {\n")
-		// Elements to return
-		buf.WriteString("\treturn nil\n")
-		buf.WriteString("}\n")
-	}
-}
-
-func printComment(buf *bytes.Buffer, comment string) {
-	comment = strings.Replace(comment, "\n", " ", -1)
-	splitComment := strings.Split(comment, " ")
-	splitComment = splitComment[:len(splitComment)-1]
-
-	// Uppercase the first letter
-	splitComment[0] = charsToUpper(splitComment[0], 0)
-	// Put a space between the function name and the ':'
-	splitComment[0] = strings.Replace(splitComment[0], ":", " :", -1)
-
-	var stop = false
-	for i := 0; i < len(splitComment); i++ {
-		var str string
-		stop = false
-		for !stop {
-			if len(str+" "+splitComment[i]) < 64 {
-				str += " " + splitComment[i]
-				if i+1 < len(splitComment) {
-					i++
-				} else {
-					break
+				if len(op.Name) > width {
+					width = len(op.Name)
 				}
-			} else {
-				stop = true
 			}
+			for _, op := range s.Operations {
+				buffer.WriteString("\tOPERATION_IDENTIFIER_" + padRight(strings.ToUpper(op.Name), width) + " = " + op.Number + "\n")
+			}
+			buffer.WriteString(")\n")
 		}
-		buf.WriteString("//" + str + "\n")
-	}
-	if stop {
-		buf.WriteString("// " + splitComment[len(splitComment)-1] + "\n")
-	}
-}
-
-func (g *Generator) createService() error {
-	filepath, err := testPath()
-	if err != nil {
-		return err
-	}
-
-	for _, service := range g.GenArea.Services {
-		var buffer = new(bytes.Buffer)
-		serviceNameToLower := strings.ToLower(service.Name)
-		servicefile := filepath + "/" + serviceNameToLower + "service/" + serviceNameToLower + "/service/service.go"
-
-		file, err := os.OpenFile(servicefile, os.O_APPEND|os.O_WRONLY, os.ModeAppend)
-		if err != nil {
-			return err
-		}
-		defer file.Close()
-
-		// TODO: Create imports
-		serviceImports(buffer, service)
-
-		// Create the structure for the Service
-		serviceStructure(buffer, service.Name)
-
-		// A method to create a new service
-		serviceCreateService(buffer, service, g.GenArea)
 
-		// Create the operations for each service
-		serviceOperations(buffer, service, g.GenArea)
-
-		_, err = file.Write(buffer.Bytes())
+		err = appendToFile(constantsfile, buffer)
 		if err != nil {
 			return err
 		}
 	}
-	return nil
-}
-
-func (g *Generator) createProvider() error {
-	for _, service := range g.GenArea.Services {
-		fmt.Println("> Provider: " + service.Name)
-	}
-	return nil
-}
-
-func (g *Generator) createConsumer() error {
-	for _, service := range g.GenArea.Services {
-		fmt.Println("> Consumer: " + service.Name)
-	}
-	return nil
-}
 
-func (g *Generator) createData() error {
-	for _, data := range g.GenArea.Composites {
-		fmt.Println("> Data: " + data.Name)
-	}
 	return nil
 }
 
-func (g *Generator) createErrors() error {
-	for _, err := range g.GenArea.Errors {
-		fmt.Println("> Error: " + err.Name)
-	}
-	return nil
-}
-
-// RetrieveInformation TODO:
+// RetrieveInformation creates the model of every area read
 func (g *Generator) RetrieveInformation() {
-	// Retrieve the area and its datas
 	for _, area := range g.xmlRaw.AreaList {
 		// Firstly, retrieve the Name, Number, Version, Comment and Requirements of the area
-		g.GenArea.Name = area.Name
-		g.GenArea.Number = area.Number
-		g.GenArea.Version = area.Version
-		g.GenArea.Comment = area.Comment
-		g.GenArea.Requirements = area.Requirements
+		a := CreateArea(area.Name, area.Number, area.Version, area.Comment, area.Requirements)
+		a.Pos = area.Pos
+		a.number = uint16(g.number(a.Pos, a.Name, "area number", a.Number, 1, maxUShort))
+		a.version = uint8(g.number(a.Pos, a.Name, "area version", a.Version, 1, maxUOctet))
+
+		// Create the fundamental and attribute types of this area
+		for _, fundamental := range area.Datas.Fundamentals {
+			f := Fundamental{
+				Name:               fundamental.Name,
+				Comment:            fundamental.Comment,
+				Pos:                fundamental.Pos,
+				NameOfTypeToExtend: fundamental.Extend.TypeToExtend.Name,
+				AreaOfTypeToExtend: fundamental.Extend.TypeToExtend.Area,
+			}
+			a.AddFundamental(f)
+		}
+		for _, attribute := range area.Datas.Attributes {
+			at := Attribute{
+				Name:          attribute.Name,
+				ShortFormPart: attribute.ShortFormPart,
+				Comment:       attribute.Comment,
+				Pos:           attribute.Pos,
+			}
+			at.shortFormPart = g.shortFormPart(at.Pos, a.Name+"::"+at.Name, at.ShortFormPart)
+			a.AddAttribute(at)
+		}
 
 		// Create the composites of this area
 		for _, composite := range area.Datas.Composites {
 			comp := createComposite(composite)
+			comp.shortFormPart = g.shortFormPart(comp.Pos, a.Name+"::"+comp.Name, comp.ShortFormPart)
 			// Then add it to the area
-			g.GenArea.AddComposite(comp)
+			a.AddComposite(comp)
+		}
+
+		// Create the enumerations of this area
+		for _, enum := range area.Datas.Enumerations {
+			e := createEnumeration(enum)
+			e.shortFormPart = g.shortFormPart(e.Pos, a.Name+"::"+e.Name, e.ShortFormPart)
+			a.AddEnumeration(e)
 		}
 
 		// Create the errors of this area
 		for _, err := range area.Errs.Errs {
-			e := Error{
-				Comment: err.Comment,
-				Name:    err.Name,
-				Number:  err.Number,
-			}
+			e := createError(err)
 			// Then add it to the area
-			g.GenArea.AddError(e)
+			a.AddError(e)
 		}
-	}
 
-	// Retrieve the service and its operations
-	for _, area := range g.xmlRaw.AreaList {
+		// Retrieve the services and their operations
 		for _, service := range area.Services {
 			s := Service{
 				Comment: service.Comment,
 				Name:    service.Name,
 				Number:  service.Number,
+				Pos:     service.Pos,
 			}
+			path := a.Name + "::" + s.Name
+			s.number = uint16(g.number(s.Pos, path, "service number", s.Number, 1, maxUShort))
 
 			// Retrieve all of the operations
 			for _, capabilitySet := range service.Capability {
@@ -483,57 +499,165 @@ func (g *Generator) RetrieveInformation() {
 			for _, comp := range service.Datas.Composites {
 				// Create the composite
 				c := createComposite(comp)
+				c.shortFormPart = g.shortFormPart(c.Pos, path+"::"+c.Name, c.ShortFormPart)
 				// Then add it to the service
 				s.AddComposite(c)
 			}
 
 			for _, enum := range service.Datas.Enumerations {
-				e := Enumeration{
-					Comment:       enum.Comment,
-					Name:          enum.Name,
-					ShortFormPart: enum.ShortFormPart,
-				}
-				for _, item := range enum.Items {
-					i := Item{
-						Comment: item.Comment,
-						NValue:  item.NValue,
-						Value:   item.Value,
-					}
-					e.AddItem(i)
-				}
+				e := createEnumeration(enum)
+				e.shortFormPart = g.shortFormPart(e.Pos, path+"::"+e.Name, e.ShortFormPart)
 				s.AddEnumeration(e)
 			}
 
+			for _, err := range service.Errs.Errs {
+				s.AddError(createError(err))
+			}
+
+			// Retrieve the COM features
+			addFeatures(&s, service.Features)
+
 			// Store this service in the area
-			g.GenArea.AddService(s)
+			a.AddService(s)
 		}
+
+		g.Areas = append(g.Areas, a)
 	}
 }
 
 func createComposite(composite data.Composite) Composite {
 	c := Composite{
-		Name:               composite.Name,
-		Comment:            composite.Comment,
-		ShortFormPart:      composite.ShortFormPart,
-		NameOfTypeToExtend: composite.Extend.TypeToExtend.Name,
-		AreaOfTypeToExtend: composite.Extend.TypeToExtend.Area,
+		Name:                  composite.Name,
+		Comment:               composite.Comment,
+		ShortFormPart:         composite.ShortFormPart,
+		Pos:                   composite.Pos,
+		NameOfTypeToExtend:    composite.Extend.TypeToExtend.Name,
+		AreaOfTypeToExtend:    composite.Extend.TypeToExtend.Area,
+		ServiceOfTypeToExtend: composite.Extend.TypeToExtend.Service,
 	}
 	for _, field := range composite.Fields {
 		f := Field{
-			CanBeNull: field.FieldCanBeNull,
-			Comment:   field.Comment,
-			Name:      field.Name,
-			TypeArea:  field.FieldType.Area,
-			TypeName:  field.FieldType.Name,
+			CanBeNull:   field.FieldCanBeNull,
+			Comment:     field.Comment,
+			Name:        field.Name,
+			Pos:         field.Pos,
+			TypeArea:    field.FieldType.Area,
+			TypeName:    field.FieldType.Name,
+			TypeService: field.FieldType.Service,
+			TypeList:    field.FieldType.List,
 		}
 		c.AddField(f)
 	}
 
-	if len(c.Fields) == 0 {
-		c.MakeAbstract()
+	return c
+}
+
+// addFeatures adds the COM objects and events of a service
+func addFeatures(s *Service, features data.Features) {
+	s.ObjectsComment = features.Objects.Comment
+	for _, object := range features.Objects.Objects {
+		s.AddObject(createObject(object, false))
+	}
+	s.EventsComment = features.Events.Comment
+	for _, event := range features.Events.Events {
+		s.AddEvent(createObject(event, true))
+	}
+	if features.ArchiveUsage != nil {
+		s.ArchiveUsage = &Usage{Comment: features.ArchiveUsage.Comment}
+	}
+	if features.ActivityUsage != nil {
+		s.ActivityUsage = &Usage{Comment: features.ActivityUsage.Comment}
 	}
+}
 
-	return c
+func createObject(object data.ModelObject, event bool) COMObject {
+	o := COMObject{
+		Name:          object.Name,
+		Number:        object.Number,
+		Comment:       object.Comment,
+		Event:         event,
+		Pos:           object.Pos,
+		ObjectType:    createType(object.ObjectType),
+		RelatedObject: createObjectReference(object.RelatedObject),
+		SourceObject:  createObjectReference(object.SourceObject),
+	}
+	return o
+}
+
+func createObjectReference(ref *data.ObjectReference) *ObjectReference {
+	if ref == nil {
+		return nil
+	}
+	return &ObjectReference{
+		Comment: ref.Comment,
+		Area:    ref.ObjectType.Area,
+		Service: ref.ObjectType.Service,
+		Number:  ref.ObjectType.Number,
+	}
+}
+
+// createEnumeration creates an enumeration with its items
+func createEnumeration(enum data.Enumeration) Enumeration {
+	e := Enumeration{
+		Comment:       enum.Comment,
+		Name:          enum.Name,
+		ShortFormPart: enum.ShortFormPart,
+		Pos:           enum.Pos,
+	}
+	for _, item := range enum.Items {
+		i := Item{
+			Comment: item.Comment,
+			NValue:  item.NValue,
+			Value:   item.Value,
+			Pos:     item.Pos,
+		}
+		e.AddItem(i)
+	}
+	return e
+}
+
+func createError(err data.Error) Error {
+	return Error{
+		Comment:          err.Comment,
+		Name:             err.Name,
+		Number:           err.Number,
+		Pos:              err.Pos,
+		ExtraInformation: createType(err.ExtraInformation.ExtraType),
+		ExtraComment:     err.ExtraInformation.Comment,
+	}
+}
+
+func createType(t data.Type) Type {
+	return Type{
+		Area:    t.Area,
+		Name:    t.Name,
+		List:    t.List,
+		Service: t.Service,
+		Pos:     t.Pos,
+	}
+}
+
+// addOperationErrors adds the errors raised by an operation
+func addOperationErrors(op *Operation, errs data.OperationErrors) {
+	for _, err := range errs.Errs {
+		e := OperationError{
+			Error: Error{
+				Comment:          err.Comment,
+				Name:             err.Name,
+				Number:           err.Number,
+				Pos:              err.Pos,
+				ExtraInformation: createType(err.ExtraInformation.ExtraType),
+				ExtraComment:     err.ExtraInformation.Comment,
+			},
+			Reference: err.IsReference(),
+		}
+		if e.Reference {
+			e.Name = err.ErrorType.Name
+			e.Area = err.ErrorType.Area
+			e.Service = err.ErrorType.Service
+		}
+		op.AddError(e)
+	}
 }
 
 // AddSendOperation TODO:
@@ -542,6 +666,7 @@ func AddSendOperation(s *Service, operation data.SendIP) {
 		Comment: operation.Comment,
 		Name:    operation.Name,
 		Number:  operation.Number,
+		Pos:     operation.Pos,
 		Pattern: PatternInteraction{
 			Name: "send",
 		},
@@ -552,12 +677,7 @@ func AddSendOperation(s *Service, operation data.SendIP) {
 		Name: "send",
 	}
 	for _, t := range operation.Message.Send.Types {
-		data := Type{
-			Area:    t.Area,
-			Name:    t.Name,
-			List:    t.List,
-			Service: t.Service,
-		}
+		data := createType(t)
 		send.AddType(data)
 	}
 	op.Pattern.AddMessage(send)
@@ -572,6 +692,7 @@ func AddSubmitOperation(s *Service, operation data.SubmitIP) {
 		Comment: operation.Comment,
 		Name:    operation.Name,
 		Number:  operation.Number,
+		Pos:     operation.Pos,
 		Pattern: PatternInteraction{
 			Name: "submit",
 		},
@@ -582,12 +703,7 @@ func AddSubmitOperation(s *Service, operation data.SubmitIP) {
 		Name: "submit",
 	}
 	for _, t := range operation.Message.Submit.Types {
-		data := Type{
-			Area:    t.Area,
-			Name:    t.Name,
-			List:    t.List,
-			Service: t.Service,
-		}
+		data := createType(t)
 		submit.AddType(data)
 	}
 	op.Pattern.AddMessage(submit)
@@ -596,8 +712,15 @@ func AddSubmitOperation(s *Service, operation data.SubmitIP) {
 	ack := Message{
 		Name: "ack",
 	}
+	for _, t := range operation.Message.Ack.Types {
+		data := createType(t)
+		ack.AddType(data)
+	}
 	op.Pattern.AddMessage(ack)
 
+	// Errors
+	addOperationErrors(&op, operation.Errs)
+
 	// Add this new operation to the service
 	s.AddOperation(op)
 }
@@ -608,6 +731,7 @@ func AddRequestOperation(s *Service, operation data.RequestIP) {
 		Comment: operation.Comment,
 		Name:    operation.Name,
 		Number:  operation.Number,
+		Pos:     operation.Pos,
 		Pattern: PatternInteraction{
 			Name: "request",
 		},
@@ -618,12 +742,7 @@ func AddRequestOperation(s *Service, operation data.RequestIP) {
 		Name: "request",
 	}
 	for _, t := range operation.Message.Request.Types {
-		data := Type{
-			Area:    t.Area,
-			Name:    t.Name,
-			List:    t.List,
-			Service: t.Service,
-		}
+		data := createType(t)
 		request.AddType(data)
 	}
 	op.Pattern.AddMessage(request)
@@ -633,16 +752,14 @@ func AddRequestOperation(s *Service, operation data.RequestIP) {
 		Name: "response",
 	}
 	for _, t := range operation.Message.Response.Types {
-		data := Type{
-			Area:    t.Area,
-			Name:    t.Name,
-			List:    t.List,
-			Service: t.Service,
-		}
+		data := createType(t)
 		response.AddType(data)
 	}
 	op.Pattern.AddMessage(response)
 
+	// Errors
+	addOperationErrors(&op, operation.Errs)
+
 	// Add this new operation to the service
 	s.AddOperation(op)
 }
@@ -653,6 +770,7 @@ func AddInvokeOPeration(s *Service, operation data.InvokeIP) {
 		Comment: operation.Comment,
 		Name:    operation.Name,
 		Number:  operation.Number,
+		Pos:     operation.Pos,
 		Pattern: PatternInteraction{
 			Name: "invoke",
 		},
@@ -663,12 +781,7 @@ func AddInvokeOPeration(s *Service, operation data.InvokeIP) {
 		Name: "invoke",
 	}
 	for _, t := range operation.Message.Invoke.Types {
-		data := Type{
-			Area:    t.Area,
-			Name:    t.Name,
-			List:    t.List,
-			Service: t.Service,
-		}
+		data := createType(t)
 		invoke.AddType(data)
 	}
 	op.Pattern.AddMessage(invoke)
@@ -677,6 +790,10 @@ func AddInvokeOPeration(s *Service, operation data.InvokeIP) {
 	ack := Message{
 		Name: "ack",
 	}
+	for _, t := range operation.Message.Ack.Types {
+		data := createType(t)
+		ack.AddType(data)
+	}
 	op.Pattern.AddMessage(ack)
 
 	// Response Message
@@ -684,16 +801,14 @@ func AddInvokeOPeration(s *Service, operation data.InvokeIP) {
 		Name: "response",
 	}
 	for _, t := range operation.Message.Response.Types {
-		data := Type{
-			Area:    t.Area,
-			Name:    t.Name,
-			List:    t.List,
-			Service: t.Service,
-		}
+		data := createType(t)
 		response.AddType(data)
 	}
 	op.Pattern.AddMessage(response)
 
+	// Errors
+	addOperationErrors(&op, operation.Errs)
+
 	// Add this new operation to the service
 	s.AddOperation(op)
 }
@@ -704,6 +819,7 @@ func AddProgressOperation(s *Service, operation data.ProgressIP) {
 		Comment: operation.Comment,
 		Name:    operation.Name,
 		Number:  operation.Number,
+		Pos:     operation.Pos,
 		Pattern: PatternInteraction{
 			Name: "progress",
 		},
@@ -714,27 +830,27 @@ func AddProgressOperation(s *Service, operation data.ProgressIP) {
 		Name: "progress",
 	}
 	for _, t := range operation.Message.Progress.Types {
-		data := Type{
-			Area:    t.Area,
-			Name:    t.Name,
-			List:    t.List,
-			Service: t.Service,
-		}
+		data := createType(t)
 		progress.AddType(data)
 	}
 	op.Pattern.AddMessage(progress)
 
-	// Invoke Message
+	// Ack Message
+	ack := Message{
+		Name: "ack",
+	}
+	for _, t := range operation.Message.Ack.Types {
+		data := createType(t)
+		ack.AddType(data)
+	}
+	op.Pattern.AddMessage(ack)
+
+	// Update Message
 	update := Message{
 		Name: "update",
 	}
 	for _, t := range operation.Message.Update.Types {
-		data := Type{
-			Area:    t.Area,
-			Name:    t.Name,
-			List:    t.List,
-			Service: t.Service,
-		}
+		data := createType(t)
 		update.AddType(data)
 	}
 	op.Pattern.AddMessage(update)
@@ -744,26 +860,26 @@ func AddProgressOperation(s *Service, operation data.ProgressIP) {
 		Name: "response",
 	}
 	for _, t := range operation.Message.Response.Types {
-		data := Type{
-			Area:    t.Area,
-			Name:    t.Name,
-			List:    t.List,
-			Service: t.Service,
-		}
+		data := createType(t)
 		response.AddType(data)
 	}
 	op.Pattern.AddMessage(response)
 
+	// Errors
+	addOperationErrors(&op, operation.Errs)
+
 	// Add this new operation to the service
 	s.AddOperation(op)
 }
 
-// AddPubSubOperation TODO:
+// AddPubSubOperation adds a publish-subscribe operation to a service,
+// its publishNotify message holds the types of the published lists
 func AddPubSubOperation(s *Service, operation data.PubSubIP) {
 	op := Operation{
 		Comment: operation.Comment,
 		Name:    operation.Name,
 		Number:  operation.Number,
+		Pos:     operation.Pos,
 		Pattern: PatternInteraction{
 			Name: "pubsub",
 		},
@@ -774,30 +890,53 @@ func AddPubSubOperation(s *Service, operation data.PubSubIP) {
 		Name: "publishNotify",
 	}
 	for _, t := range operation.Message.PublishNotify.Types {
-		data := Type{
-			Area:    t.Area,
-			Name:    t.Name,
-			List:    t.List,
-			Service: t.Service,
-		}
+		data := createType(t)
 		publishNotify.AddType(data)
 	}
 	op.Pattern.AddMessage(publishNotify)
 
+	// Errors
+	addOperationErrors(&op, operation.Errs)
+
 	// Add this new operation to the service
 	s.AddOperation(op)
 }
 
-func testPath() (string, error) {
-	path := "../"
-	testpath := path + "tests/"
-	filepath, err := filepath.Abs(testpath)
+// outputPath returns the absolute path of the output root
+func (g *Generator) outputPath() (string, error) {
+	path := g.OutputPath
+	if path == "" {
+		path = DefaultOutputPath
+	}
+	filepath, err := filepath.Abs(path)
 	if err != nil {
 		return "", err
 	}
 	return filepath, nil
 }
 
+// areaPath returns the absolute path of the package tree of an area
+func (g *Generator) areaPath(area Area) (string, error) {
+	path, err := g.outputPath()
+	if err != nil {
+		return "", err
+	}
+	return path + "/" + strings.ToLower(area.Name), nil
+}
+
+// areaModulePath returns the import path of the package tree of an area
+func (g *Generator) areaModulePath(area Area) string {
+	return g.modulePath() + "/" + strings.ToLower(area.Name)
+}
+
+// modulePath returns the import path of the output root
+func (g *Generator) modulePath() string {
+	if g.ModulePath == "" {
+		return DefaultModulePath
+	}
+	return strings.TrimSuffix(g.ModulePath, "/")
+}
+
 func serviceIdentifier(s Service) string {
 	return strings.ToUpper(s.Name) + "_SERVICE_SERVICE_IDENTIFIER"
 }
@@ -810,6 +949,14 @@ func areaIdentifier(s Service) string {
 	return strings.ToUpper(s.Name) + "_SERVICE_AREA_IDENTIFIER"
 }
 
+func areaNumber(s Service) string {
+	return strings.ToUpper(s.Name) + "_SERVICE_AREA_NUMBER"
+}
+
+func areaVersion(s Service) string {
+	return strings.ToUpper(s.Name) + "_SERVICE_AREA_VERSION"
+}
+
 func charsToLower(str string, pos ...int) string {
 	splitstr := strings.Split(str, "")
 	for i := 0; i < len(pos); i++ {
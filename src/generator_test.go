@@ -0,0 +1,463 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2018 CNES
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package src
+
+import (
+	"bytes"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// generate writes the files of all the areas of a generator in a
+// temporary directory and returns it
+func generate(t *testing.T, g *Generator) string {
+	t.Helper()
+	g.OutputPath = t.TempDir()
+	for _, area := range g.Areas {
+		err := g.InitDirectories(area)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = g.CreateInformation(area)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return g.OutputPath
+}
+
+// generateSpecification generates the MAL and the COM bundled with the
+// generator
+func generateSpecification(t *testing.T) string {
+	t.Helper()
+	g := new(Generator)
+	for _, file := range []string{malSpecification, comSpecification} {
+		err := g.OpenAndReadXML(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	g.RetrieveInformation()
+	err := g.Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return generate(t, g)
+}
+
+// readGenerated returns the content of a generated file, path is
+// relative to the output directory
+func readGenerated(t *testing.T, output string, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join(output, path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+// checkContains checks that a generated file contains each of the
+// snippets given
+func checkContains(t *testing.T, content string, snippets ...string) {
+	t.Helper()
+	for _, s := range snippets {
+		if !strings.Contains(content, s) {
+			t.Errorf("generated code does not contain:\n%s", s)
+		}
+	}
+}
+
+func TestGenerateFormatted(t *testing.T) {
+	output := generateSpecification(t)
+
+	err := filepath.Walk(output, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		formatted, err := format.Source(content)
+		if err != nil {
+			t.Errorf("%s: %v", path, err)
+		} else if !bytes.Equal(content, formatted) {
+			t.Errorf("%s is not formatted", path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The packages of the standard library are imported first
+	provider := readGenerated(t, output, "com/archiveservice/archive/provider/provider.go")
+	checkContains(t, provider, "import (\n\t\"errors\"\n\n\t\"github.com/ccsdsmo/malgo/mal\"\n")
+}
+
+// generateTestArea generates the MAL and the test area with the
+// operations and the data types given
+func generateTestArea(t *testing.T, operations string, dataTypes string) string {
+	t.Helper()
+	g, err := loadTestArea(t, operations, dataTypes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return generate(t, g)
+}
+
+// progressOperation is a progress operation of the test service without
+// response
+const progressOperation = `<mal:progressIP name="watch" number="2" supportInReplay="false">
+          <mal:messages>
+            <mal:progress/>
+            <mal:acknowledgement/>
+            <mal:update>
+              <mal:type name="String" area="MAL"/>
+            </mal:update>
+            <mal:response/>
+          </mal:messages>
+        </mal:progressIP>
+`
+
+func TestGenerateProgressWithoutResponse(t *testing.T) {
+	output := generateTestArea(t, progressOperation, "")
+
+	consumer := readGenerated(t, output, "test/testservice/test/consumer/consumer.go")
+	checkContains(t, consumer,
+		"progress.err = func() error {\n",
+		"func (p *WatchProgress) Response() error {\n",
+	)
+	// The updates are not sent anymore once the progress is closed
+	checkContains(t, consumer,
+		"\t\t\t\tselect {\n\t\t\t\tcase updates <- &WatchUpdate{stringValue}:\n\t\t\t\tcase <-progress.stop:\n\t\t\t\t\treturn ErrClosed\n",
+		"func (p *WatchProgress) Close() {\n",
+	)
+	provider := readGenerated(t, output, "test/testservice/test/provider/provider.go")
+	checkContains(t, provider, "Watch(acknowledge func() error, updater *WatchUpdater) error\n")
+}
+
+func TestGenerateProviderErrors(t *testing.T) {
+	output := generateSpecification(t)
+
+	provider := readGenerated(t, output, "com/archiveservice/archive/provider/provider.go")
+	checkContains(t, provider,
+		"\tmalerrors \"github.com/etiennelndr/tests/mal/errors\"\n",
+		"\tnumber := mal.UInteger(malerrors.INTERNAL)\n",
+	)
+}
+
+func TestGenerateConsumerErrors(t *testing.T) {
+	output := generateSpecification(t)
+
+	consumer := readGenerated(t, output, "com/archiveservice/archive/consumer/consumer.go")
+	checkContains(t, consumer,
+		"\tcase mal.UInteger(comerrors.INVALID):\n\t\treturn archiveerrors.NewRetrieveInvalidError()\n",
+		"\tcase mal.UInteger(malerrors.UNKNOWN):\n",
+	)
+}
+
+func TestGenerateOperationErrors(t *testing.T) {
+	output := generateSpecification(t)
+
+	errors := readGenerated(t, output, "com/archiveservice/errors/errors.go")
+	checkContains(t, errors,
+		"\tcomerrors \"github.com/etiennelndr/tests/com/errors\"\n",
+		"func (e *RetrieveInvalidError) Number() mal.UInteger {\n\treturn mal.UInteger(comerrors.INVALID)\n}\n",
+	)
+}
+
+func TestGenerateParameterNames(t *testing.T) {
+	operations := `<mal:requestIP name="echo" number="2" supportInReplay="false">
+          <mal:messages>
+            <mal:request>
+              <mal:type name="String" area="MAL"/>
+              <mal:type name="String" area="MAL"/>
+            </mal:request>
+            <mal:response>
+              <mal:type name="Kind" service="Test" area="TEST"/>
+            </mal:response>
+          </mal:messages>
+        </mal:requestIP>
+`
+	output := generateTestArea(t, operations, "")
+
+	consumer := readGenerated(t, output, "test/testservice/test/consumer/consumer.go")
+	checkContains(t, consumer, "Echo(stringValue *mal.String, stringValue2 *mal.String) (*test.Kind, error)")
+	provider := readGenerated(t, output, "test/testservice/test/provider/provider.go")
+	checkContains(t, provider, "Echo(stringValue *mal.String, stringValue2 *mal.String) (*test.Kind, error)")
+}
+
+func TestGenerateCompositeComments(t *testing.T) {
+	dataTypes := composite("Base", "", "Composite") + composite("Sample", "2", "Base") +
+		`<mal:composite name="Noted" shortFormPart="3" comment="Holds a note">
+          <mal:extends>
+            <mal:type name="Base" area="TEST"/>
+          </mal:extends>
+        </mal:composite>
+`
+	output := generateTestArea(t, "", dataTypes)
+
+	data := readGenerated(t, output, "test/testservice/data/data.go")
+	checkContains(t, data,
+		"// Base is an abstract composite. Its concrete subtypes are\n// TEST::Test::Sample, TEST::Test::Noted.\ntype Base interface {\n",
+		"// Sample is the TEST::Test::Sample composite\ntype Sample struct {\n",
+		"// Holds a note\ntype Noted struct {\n",
+	)
+}
+
+func TestGenerateEnumerationComments(t *testing.T) {
+	dataTypes := `<mal:enumeration name="Noted" shortFormPart="2" comment="Holds a note">
+          <mal:item value="FIRST" nvalue="1"/>
+        </mal:enumeration>
+`
+	output := generateTestArea(t, "", dataTypes)
+
+	data := readGenerated(t, output, "test/testservice/data/data.go")
+	checkContains(t, data,
+		"// Kind is the TEST::Test::Kind enumeration\ntype Kind uint32\n",
+		"// Holds a note\ntype Noted uint32\n",
+	)
+}
+
+// notedOperation is a send operation of the test service with a comment
+const notedOperation = `<mal:sendIP name="note" number="2" supportInReplay="false" comment="Sends a note.">
+          <mal:messages>
+            <mal:send/>
+          </mal:messages>
+        </mal:sendIP>
+`
+
+func TestGenerateHandlerComments(t *testing.T) {
+	output := generateTestArea(t, notedOperation, "")
+
+	provider := readGenerated(t, output, "test/testservice/test/provider/provider.go")
+	checkContains(t, provider,
+		"\t// Ping handles the ping send operation\n\tPing()\n",
+		"\t// Sends a note.\n\tNote()\n",
+	)
+}
+
+func TestGenerateClientComments(t *testing.T) {
+	output := generateTestArea(t, notedOperation, "")
+
+	consumer := readGenerated(t, output, "test/testservice/test/consumer/consumer.go")
+	checkContains(t, consumer,
+		"// Ping calls the ping send operation\nfunc (c *Client) Ping() error {\n",
+		"// Sends a note.\nfunc (c *Client) Note() error {\n",
+	)
+}
+
+func TestGenerateObjects(t *testing.T) {
+	output := generateSpecification(t)
+
+	activity := readGenerated(t, output, "com/activitytrackingservice/activitytracking/objects/objects.go")
+	checkContains(t, activity,
+		"var OperationActivityObjectType = com.ObjectType{\n",
+		"func NewBody(number mal.UShort) mal.Element {\n\tswitch number {\n\tcase OPERATION_ACTIVITY_OBJECT_NUMBER:\n\t\treturn new(OperationActivityBody)\n",
+	)
+	// The objects and the events of the Archive service have no body
+	archive := readGenerated(t, output, "com/archiveservice/archive/objects/objects.go")
+	checkContains(t, archive, "func NewBody(number mal.UShort) mal.Element {\n\treturn nil\n}\n")
+}
+
+// sampleComposite is a composite of the test service with a field which
+// can't be NULL and one which can
+const sampleComposite = `<mal:composite name="Sample" shortFormPart="2">
+          <mal:extends>
+            <mal:type name="Composite" area="MAL"/>
+          </mal:extends>
+          <mal:field name="id" canBeNull="false">
+            <mal:type name="Long" area="MAL"/>
+          </mal:field>
+          <mal:field name="label">
+            <mal:type name="String" area="MAL"/>
+          </mal:field>
+        </mal:composite>
+`
+
+func TestGenerateEncoding(t *testing.T) {
+	output := generateTestArea(t, "", sampleComposite)
+
+	data := readGenerated(t, output, "test/testservice/data/data.go")
+	checkContains(t, data,
+		"type Sample struct {\n\tId    mal.Long\n\tLabel *mal.String\n}\n",
+		"\terr = encoder.EncodeLong(&c.Id)\n",
+		"\terr = encoder.EncodeNullableString(c.Label)\n",
+		"\tid, err := decoder.DecodeLong()\n",
+		"\tcomposite.Id = *id\n",
+		"\tlabel, err := decoder.DecodeNullableString()\n",
+		"\tcomposite.Label = label\n",
+	)
+}
+
+func TestGenerateLists(t *testing.T) {
+	output := generateTestArea(t, "", sampleComposite)
+
+	data := readGenerated(t, output, "test/testservice/data/data.go")
+	checkContains(t, data,
+		"type SampleList []*Sample\n",
+		"\tSAMPLE_LIST_TYPE_SHORT_FORM mal.Integer = -2\n\tSAMPLE_LIST_SHORT_FORM      mal.Long    = 0x64000101fffffe\n",
+		"func (list *SampleList) AppendElement(element mal.Element) {\n\tentry, _ := element.(*Sample)\n",
+		"type KindList []*Kind\n",
+		"\tKIND_LIST_TYPE_SHORT_FORM mal.Integer = -1\n",
+	)
+}
+
+func TestGenerateEnumerations(t *testing.T) {
+	dataTypes := `<mal:enumeration name="Mode" shortFormPart="2">
+          <mal:item value="AUTOMATIC" nvalue="1"/>
+          <mal:item value="MANUAL" nvalue="5"/>
+        </mal:enumeration>
+`
+	output := generateTestArea(t, "", dataTypes)
+
+	data := readGenerated(t, output, "test/testservice/data/data.go")
+	checkContains(t, data,
+		"\tMODE_AUTOMATIC Mode = 0\n\tMODE_MANUAL    Mode = 1\n",
+		"var numericValuesMode = []mal.UInteger{\n\t1,\n\t5,\n}\n",
+		"var namesMode = []string{\n\t\"AUTOMATIC\",\n\t\"MANUAL\",\n}\n",
+		"func ModeFromNumericValue(value mal.UInteger) (Mode, error) {\n",
+		"func ParseMode(s string) (Mode, error) {\n",
+		"\tordinal := mal.UOctet(*e)\n\treturn encoder.EncodeUOctet(&ordinal)\n",
+	)
+}
+
+func TestGenerateAreaEnumerations(t *testing.T) {
+	content := strings.Replace(strings.Replace(testArea, "%s", "", 2), "  </mal:area>", `    <mal:dataTypes>
+      <mal:enumeration name="Level" shortFormPart="1">
+        <mal:item value="LOW" nvalue="1"/>
+      </mal:enumeration>
+    </mal:dataTypes>
+  </mal:area>`, 1)
+	path := filepath.Join(t.TempDir(), "ServiceDefTEST.xml")
+	err := os.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := new(Generator)
+	for _, file := range []string{malSpecification, path} {
+		err = g.OpenAndReadXML(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	g.RetrieveInformation()
+	err = g.Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Areas[1].Enumerations) != 1 {
+		t.Fatalf("enumerations of TEST = %v, want Level", g.Areas[1].Enumerations)
+	}
+	output := generate(t, g)
+
+	data := readGenerated(t, output, "test/data/data.go")
+	checkContains(t, data,
+		"// Level is the TEST::Level enumeration\ntype Level uint32\n",
+		"\tLEVEL_TYPE_SHORT_FORM mal.Integer = 1\n\tLEVEL_SHORT_FORM      mal.Long    = 0x64000001000001\n",
+	)
+}
+
+func TestGeneratePubSub(t *testing.T) {
+	operations := `<mal:pubsubIP name="monitor" number="2" supportInReplay="false">
+          <mal:messages>
+            <mal:publishNotify>
+              <mal:type name="Sample" service="Test" area="TEST" list="true"/>
+            </mal:publishNotify>
+          </mal:messages>
+        </mal:pubsubIP>
+`
+	output := generateTestArea(t, operations, sampleComposite)
+
+	provider := readGenerated(t, output, "test/testservice/test/provider/provider.go")
+	checkContains(t, provider,
+		"func (p *Provider) NewMonitorPublisher(brokerURI *mal.URI) *MonitorPublisher {\n",
+		"func (pub *MonitorPublisher) Publish(updateHeaders *mal.UpdateHeaderList, sampleList *test.SampleList) error {\n",
+	)
+	consumer := readGenerated(t, output, "test/testservice/test/consumer/consumer.go")
+	checkContains(t, consumer,
+		"type MonitorNotify func(subscriptionID *mal.Identifier, updateHeaders *mal.UpdateHeaderList, sampleList *test.SampleList) error\n",
+		"func (c *Client) NewMonitorSubscriber(brokerURI *mal.URI) *MonitorSubscriber {\n",
+		"\t\terr = notify(subscriptionID, updateHeaders, sampleList)\n",
+	)
+	// The publish-subscribe operations are not handled by the provider
+	if strings.Contains(provider, "\tMonitor(") {
+		t.Error("the handler has a method for the monitor operation")
+	}
+}
+
+func TestGenerateEntityKeys(t *testing.T) {
+	output := generateSpecification(t)
+
+	data := readGenerated(t, output, "com/data/data.go")
+	checkContains(t, data,
+		"func ObjectNumberSubKey(number mal.UShort) *mal.Identifier {\n",
+		"func NewEventEntityKey(eventType *ObjectType, instId mal.Long, sourceType *ObjectType) *mal.EntityKey {\n",
+	)
+}
+
+func TestGenerateAcknowledgements(t *testing.T) {
+	operations := `<mal:invokeIP name="run" number="2" supportInReplay="false">
+          <mal:messages>
+            <mal:invoke>
+              <mal:type name="Sample" service="Test" area="TEST"/>
+            </mal:invoke>
+            <mal:acknowledgement>
+              <mal:type name="Long" area="MAL"/>
+            </mal:acknowledgement>
+            <mal:response>
+              <mal:type name="Kind" service="Test" area="TEST" list="true"/>
+            </mal:response>
+          </mal:messages>
+        </mal:invokeIP>
+        <mal:progressIP name="follow" number="3" supportInReplay="false">
+          <mal:messages>
+            <mal:progress/>
+            <mal:acknowledgement>
+              <mal:type name="Long" area="MAL"/>
+            </mal:acknowledgement>
+            <mal:update/>
+            <mal:response/>
+          </mal:messages>
+        </mal:progressIP>
+`
+	output := generateTestArea(t, operations, sampleComposite)
+
+	provider := readGenerated(t, output, "test/testservice/test/provider/provider.go")
+	checkContains(t, provider,
+		"\tRun(sample *test.Sample, acknowledge func(long *mal.Long) error) (*test.KindList, error)\n",
+		"\terr = acknowledge(nil)\n",
+	)
+	consumer := readGenerated(t, output, "test/testservice/test/consumer/consumer.go")
+	checkContains(t, consumer,
+		"func (c *Client) Run(sample *test.Sample) (*mal.Long, *test.KindList, error) {\n",
+		"func (p *FollowProgress) Acknowledgement() *mal.Long {\n\treturn p.long\n}\n",
+	)
+}
@@ -0,0 +1,215 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2018 CNES
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package src
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// comArea is the name of the COM area which defines the types used to
+// identify the objects
+const comArea = "COM"
+
+// createObjects writes the COM object types of the objects and the
+// events of each service in its objects package
+func (g *Generator) createObjects(area Area) error {
+	filepath, err := g.areaPath(area)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range area.Services {
+		if len(s.Objects)+len(s.Events) == 0 {
+			continue
+		}
+		g.logf("> Objects: %s", s.Name)
+
+		var body = new(bytes.Buffer)
+		imp := imports{}
+		imp.add("mal", malImport)
+		objectType := g.goType(Type{Name: "ObjectType", Area: comArea}, imp, "")
+		objectKey := g.goType(Type{Name: "ObjectKey", Area: comArea}, imp, "")
+		objectID := g.goType(Type{Name: "ObjectId", Area: comArea}, imp, "")
+
+		// Numbers of the objects
+		body.WriteString("\n// Numbers of the COM objects and events of the " + s.Name + " service\n")
+		body.WriteString("const (\n")
+		width := 0
+		for _, o := range append(s.Objects, s.Events...) {
+			if len(objectNumber(o)) > width {
+				width = len(objectNumber(o))
+			}
+		}
+		for _, o := range append(s.Objects, s.Events...) {
+			body.WriteString("\t" + padRight(objectNumber(o), width) + " mal.UShort = " + o.Number + "\n")
+		}
+		body.WriteString(")\n")
+
+		// Key of an object
+		body.WriteString("\n// NewObjectKey creates the key of an object instance in a domain\n")
+		body.WriteString("func NewObjectKey(domain mal.IdentifierList, instId mal.Long) *" + objectKey + " {\n")
+		body.WriteString("\treturn &" + objectKey + "{\n")
+		body.WriteString("\t\tDomain: domain,\n")
+		body.WriteString("\t\tInstId: instId,\n")
+		body.WriteString("\t}\n")
+		body.WriteString("}\n")
+
+		for _, o := range append(s.Objects, s.Events...) {
+			g.comObject(body, imp, o, s, area, objectType, objectID)
+		}
+
+		// Bodies of the objects
+		body.WriteString("\n// NewBody creates an empty body for the object or the event number,\n")
+		body.WriteString("// nil is returned if it has no body\n")
+		body.WriteString("func NewBody(number mal.UShort) mal.Element {\n")
+		var cases []string
+		for _, o := range append(s.Objects, s.Events...) {
+			if o.HasBody() && !g.isAbstract(o.ObjectType) {
+				cases = append(cases, "\tcase "+objectNumber(o)+":\n\t\treturn new("+o.Name+"Body)\n")
+			}
+		}
+		if len(cases) != 0 {
+			body.WriteString("\tswitch number {\n")
+			body.WriteString(strings.Join(cases, ""))
+			body.WriteString("\t}\n")
+		}
+		body.WriteString("\treturn nil\n")
+		body.WriteString("}\n")
+
+		var buffer = new(bytes.Buffer)
+		imp.write(buffer)
+		buffer.Write(body.Bytes())
+
+		serviceNameToLower := strings.ToLower(s.Name)
+		objectsfile := filepath + "/" + serviceNameToLower + "service/" + serviceNameToLower + "/objects/objects.go"
+		err = appendToFile(objectsfile, buffer)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// comObject writes the object type of a COM object, the functions to
+// identify its instances and its body type
+func (g *Generator) comObject(buf *bytes.Buffer, imp imports, o COMObject, s Service, a Area, objectType string, objectID string) {
+	kind := "object"
+	if o.Event {
+		kind = "event"
+	}
+
+	buf.WriteString("\n")
+	writeComment(buf, "", o.Name+"ObjectType is the object type of the "+o.Name+" "+kind+". "+o.Comment)
+	buf.WriteString("var " + o.Name + "ObjectType = " + objectType + "{\n")
+	buf.WriteString("\tArea:    " + strconv.Itoa(int(a.number)) + ",\n")
+	buf.WriteString("\tService: " + strconv.Itoa(int(s.number)) + ",\n")
+	buf.WriteString("\tVersion: " + strconv.Itoa(int(a.version)) + ",\n")
+	buf.WriteString("\tNumber:  " + objectNumber(o) + ",\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// New" + o.Name + "ObjectId creates the identifier of an instance of the " + o.Name + " " + kind + "\n")
+	buf.WriteString("func New" + o.Name + "ObjectId(domain mal.IdentifierList, instId mal.Long) *" + objectID + " {\n")
+	buf.WriteString("\treturn &" + objectID + "{\n")
+	buf.WriteString("\t\tType: " + o.Name + "ObjectType,\n")
+	buf.WriteString("\t\tKey:  *NewObjectKey(domain, instId),\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString("}\n")
+
+	if o.HasBody() {
+		buf.WriteString("\n// " + o.Name + "Body is the type of the body of the " + o.Name + " " + kind + "\n")
+		buf.WriteString("type " + o.Name + "Body = " + g.goType(o.ObjectType, imp, "") + "\n")
+	}
+}
+
+// entityKeys writes the functions building the sub-keys of the entity
+// keys identifying the COM objects in the publish-subscribe operations,
+// as defined by the monitorEvent operation of the Event service
+func (g *Generator) entityKeys(buf *bytes.Buffer, imp imports) {
+	imp.add("mal", malImport)
+	imp.add("strconv", "strconv")
+
+	buf.WriteString("\n// ObjectNumberSubKey returns the sub-key of an object number, the number\n")
+	buf.WriteString("// as a base 10 string without padding\n")
+	buf.WriteString("func ObjectNumberSubKey(number mal.UShort) *mal.Identifier {\n")
+	buf.WriteString("\treturn mal.NewIdentifier(strconv.FormatUint(uint64(number), 10))\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// ServiceSubKey returns the sub-key of the area, the service and the\n")
+	buf.WriteString("// version of an object type, 0xAAAASSSSVV000000 in hex\n")
+	buf.WriteString("func ServiceSubKey(objectType *ObjectType) *mal.Long {\n")
+	buf.WriteString("\tkey := uint64(objectType.Area)<<48 | uint64(objectType.Service)<<32 | uint64(objectType.Version)<<24\n")
+	buf.WriteString("\treturn mal.NewLong(int64(key))\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// ObjectTypeSubKey returns the sub-key of an object type, its area, its\n")
+	buf.WriteString("// service, its version and its number, 0xAAAASSSSVVNNNNNN in hex\n")
+	buf.WriteString("func ObjectTypeSubKey(objectType *ObjectType) *mal.Long {\n")
+	buf.WriteString("\tkey := uint64(objectType.Area)<<48 | uint64(objectType.Service)<<32 | uint64(objectType.Version)<<24 | uint64(objectType.Number)\n")
+	buf.WriteString("\treturn mal.NewLong(int64(key))\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// NewEventEntityKey creates the entity key of an event instance published\n")
+	buf.WriteString("// with the monitorEvent operation, sourceType is the object type of the\n")
+	buf.WriteString("// source of the event, nil if it has none\n")
+	buf.WriteString("func NewEventEntityKey(eventType *ObjectType, instId mal.Long, sourceType *ObjectType) *mal.EntityKey {\n")
+	buf.WriteString("\tkey := &mal.EntityKey{\n")
+	buf.WriteString("\t\tFirstSubKey:  ObjectNumberSubKey(eventType.Number),\n")
+	buf.WriteString("\t\tSecondSubKey: ServiceSubKey(eventType),\n")
+	buf.WriteString("\t\tThirdSubKey:  mal.NewLong(int64(instId)),\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString("\tif sourceType != nil {\n")
+	buf.WriteString("\t\tkey.FourthSubKey = ObjectTypeSubKey(sourceType)\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString("\treturn key\n")
+	buf.WriteString("}\n")
+}
+
+// objectNumber returns the name of the constant holding the number of
+// a COM object, e.g. OBJECT_STORED_EVENT_NUMBER
+func objectNumber(o COMObject) string {
+	if o.Event {
+		return upperSnakeCase(o.Name) + "_EVENT_NUMBER"
+	}
+	return upperSnakeCase(o.Name) + "_OBJECT_NUMBER"
+}
+
+// upperSnakeCase converts a type name to a constant name, e.g.
+// ObjectStored becomes OBJECT_STORED
+func upperSnakeCase(name string) string {
+	var res string
+	for i, c := range name {
+		if i > 0 && c >= 'A' && c <= 'Z' {
+			prev := name[i-1]
+			if prev >= 'a' && prev <= 'z' || i+1 < len(name) && name[i+1] >= 'a' && name[i+1] <= 'z' && prev >= 'A' && prev <= 'Z' {
+				res += "_"
+			}
+		}
+		res += string(c)
+	}
+	return strings.ToUpper(res)
+}
@@ -0,0 +1,552 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2018 CNES
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package src
+
+import (
+	"bytes"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// dataFile is a data file being generated, self is the import path of
+// its package and the names and the short form without short form part
+// identify the types it holds
+type dataFile struct {
+	path      string
+	self      string
+	area      string
+	service   string
+	shortForm ShortForm
+}
+
+// createData writes the data types of the area in its data package and
+// the ones of each service in the data package of the service. The MAL
+// data types are the ones of the MAL API.
+func (g *Generator) createData(area Area) error {
+	filepath, err := g.areaPath(area)
+	if err != nil {
+		return err
+	}
+
+	if area.Name != malArea {
+		_, self := g.dataPackage(area.Name, "")
+		file := dataFile{filepath + "/data/data.go", self, area.Name, "", baseShortForm(area, Service{})}
+		err = g.writeData(file, area.Composites, area.Enumerations)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, s := range area.Services {
+		_, self := g.dataPackage(area.Name, s.Name)
+		serviceNameToLower := strings.ToLower(s.Name)
+		file := dataFile{filepath + "/" + serviceNameToLower + "service/data/data.go", self, area.Name, s.Name, baseShortForm(area, s)}
+		err = g.writeData(file, s.Composites, s.Enumerations)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeData appends the enumerations and the composites to a data file,
+// preceded by the numbers identifying its types
+func (g *Generator) writeData(file dataFile, composites []Composite, enumerations []Enumeration) error {
+	var body = new(bytes.Buffer)
+	imp := imports{}
+	for _, e := range enumerations {
+		g.logf("> Enumeration: %s", e.Name)
+		g.enumeration(body, imp, e, file)
+		g.list(body, imp, e.Name, nil, file)
+	}
+	for _, c := range composites {
+		g.logf("> Data: %s", c.Name)
+		if c.IsAbstract() {
+			g.abstractComposite(body, imp, c, file)
+		} else {
+			g.composite(body, imp, c, file)
+		}
+		if c.IsAbstract() {
+			g.abstractList(body, imp, c, file.self)
+		} else {
+			g.list(body, imp, c.Name, g.ancestors(c), file)
+		}
+	}
+	if _, self := g.dataPackage(comArea, ""); file.self == self {
+		g.entityKeys(body, imp)
+	}
+	if body.Len() == 0 {
+		return nil
+	}
+
+	var buffer = new(bytes.Buffer)
+	imp.write(buffer)
+	buffer.WriteString("\n// Numbers identifying the types of this package\n")
+	buffer.WriteString("const (\n")
+	buffer.WriteString("\tAREA_NUMBER    mal.UShort = " + strconv.Itoa(int(file.shortForm.Area)) + "\n")
+	buffer.WriteString("\tAREA_VERSION   mal.UOctet = " + strconv.Itoa(int(file.shortForm.Version)) + "\n")
+	buffer.WriteString("\tSERVICE_NUMBER mal.UShort = " + strconv.Itoa(int(file.shortForm.Service)) + "\n")
+	buffer.WriteString(")\n")
+	buffer.Write(body.Bytes())
+	return appendToFile(file.path, buffer)
+}
+
+// abstractComposite writes an abstract composite as an interface, its
+// concrete subtypes implement it with a method of the same name
+func (g *Generator) abstractComposite(buf *bytes.Buffer, imp imports, c Composite, file dataFile) {
+	imp.add("mal", malImport)
+	self := file.self
+
+	var subtypes []string
+	for _, t := range g.ConcreteSubtypes(Type{Name: c.Name, Area: file.area, Service: file.service}) {
+		subtypes = append(subtypes, t.String())
+	}
+	var sentences []string
+	if len(subtypes) != 0 {
+		sentences = append(sentences, "Its concrete subtypes are "+strings.Join(subtypes, ", ")+".")
+	}
+
+	buf.WriteString("\n")
+	writeComment(buf, "", docComment(c.Comment, c.Name+" is an abstract composite", sentences...))
+	buf.WriteString("type " + c.Name + " interface {\n")
+	buf.WriteString("\tmal.Composite\n")
+	for _, parent := range g.ancestors(c) {
+		if parent.IsAbstract() {
+			buf.WriteString("\t" + g.compositeType(parent, imp, self) + "\n")
+		}
+	}
+	buf.WriteString("\t" + c.Name + "() " + c.Name + "\n")
+	buf.WriteString("}\n")
+}
+
+// composite writes a concrete composite as a structure, the fields of
+// its parents come first
+func (g *Generator) composite(buf *bytes.Buffer, imp imports, c Composite, file dataFile) {
+	self := file.self
+	ancestors := g.ancestors(c)
+	fields := c.AllFields()
+
+	buf.WriteString("\n")
+	t := Type{Name: c.Name, Area: file.area, Service: file.service}
+	writeComment(buf, "", docComment(c.Comment, c.Name+" is the "+t.String()+" composite"))
+	buf.WriteString("type " + c.Name + " struct {\n")
+	for _, f := range fields {
+		writeComment(buf, "\t", f.Comment)
+		buf.WriteString("\t" + charsToUpper(f.Name, 0) + " " + g.fieldType(f, imp, self) + "\n")
+	}
+	buf.WriteString("}\n")
+
+	// Abstract parents implemented by the composite
+	for _, parent := range ancestors {
+		if !parent.IsAbstract() {
+			continue
+		}
+		parentType := g.compositeType(parent, imp, self)
+		buf.WriteString("\n// " + parent.Name + " implements the " + parentType + " interface\n")
+		buf.WriteString("func (c *" + c.Name + ") " + parent.Name + "() " + parentType + " {\n")
+		buf.WriteString("\treturn c\n")
+		buf.WriteString("}\n")
+	}
+
+	g.compositeEncoding(buf, imp, c, fields, file)
+}
+
+// compositeEncoding writes the methods of the mal.Composite interface of
+// a concrete composite, its fields are encoded in order
+func (g *Generator) compositeEncoding(buf *bytes.Buffer, imp imports, c Composite, fields []Field, file dataFile) {
+	imp.add("mal", malImport)
+	constant := upperSnakeCase(c.Name)
+	g.writeShortForms(buf, c.Name, constant, Type{Name: c.Name, Area: file.area, Service: file.service})
+
+	buf.WriteString("\n// Null" + c.Name + " is the NULL value of the " + c.Name + " type\n")
+	buf.WriteString("var Null" + c.Name + " *" + c.Name + " = nil\n")
+
+	buf.WriteString("\n// New" + c.Name + " creates a new " + c.Name + "\n")
+	buf.WriteString("func New" + c.Name + "() *" + c.Name + " {\n")
+	buf.WriteString("\treturn new(" + c.Name + ")\n")
+	buf.WriteString("}\n")
+
+	// Type identifiers
+	buf.WriteString("\n// Composite implements the mal.Composite interface\n")
+	buf.WriteString("func (c *" + c.Name + ") Composite() mal.Composite {\n")
+	buf.WriteString("\treturn c\n")
+	buf.WriteString("}\n")
+	writeTypeIdentifiers(buf, c.Name, constant)
+
+	// Element
+	buf.WriteString("\n// CreateElement creates an empty " + c.Name + "\n")
+	buf.WriteString("func (*" + c.Name + ") CreateElement() mal.Element {\n")
+	buf.WriteString("\treturn new(" + c.Name + ")\n")
+	buf.WriteString("}\n")
+	buf.WriteString("\n// IsNull checks if the " + c.Name + " is NULL\n")
+	buf.WriteString("func (c *" + c.Name + ") IsNull() bool {\n")
+	buf.WriteString("\treturn c == nil\n")
+	buf.WriteString("}\n")
+	buf.WriteString("\n// Null returns the NULL value of the " + c.Name + " type\n")
+	buf.WriteString("func (*" + c.Name + ") Null() mal.Element {\n")
+	buf.WriteString("\treturn Null" + c.Name + "\n")
+	buf.WriteString("}\n")
+
+	// Encoding
+	buf.WriteString("\n// Encode encodes the fields of the " + c.Name + "\n")
+	buf.WriteString("func (c *" + c.Name + ") Encode(encoder mal.Encoder) error {\n")
+	if len(fields) == 0 {
+		buf.WriteString("\treturn nil\n")
+	} else {
+		buf.WriteString("\tvar err error\n")
+		for _, f := range fields {
+			buf.WriteString("\terr = encoder." + g.encodeField(f) + "\n")
+			buf.WriteString("\tif err != nil {\n")
+			buf.WriteString("\t\treturn err\n")
+			buf.WriteString("\t}\n")
+		}
+		buf.WriteString("\treturn nil\n")
+	}
+	buf.WriteString("}\n")
+
+	// Decoding
+	buf.WriteString("\n// Decode decodes a " + c.Name + "\n")
+	buf.WriteString("func (c *" + c.Name + ") Decode(decoder mal.Decoder) (mal.Element, error) {\n")
+	buf.WriteString("\treturn Decode" + c.Name + "(decoder)\n")
+	buf.WriteString("}\n")
+	buf.WriteString("\n// Decode" + c.Name + " decodes the fields of a " + c.Name + "\n")
+	buf.WriteString("func Decode" + c.Name + "(decoder mal.Decoder) (*" + c.Name + ", error) {\n")
+	buf.WriteString("\tvar composite " + c.Name + "\n")
+	for _, f := range fields {
+		g.decodeField(buf, imp, f, file.self)
+	}
+	buf.WriteString("\treturn &composite, nil\n")
+	buf.WriteString("}\n")
+}
+
+// encodeField returns the call of the encoder encoding a field
+func (g *Generator) encodeField(f Field) string {
+	t := f.Type()
+	name := "c." + charsToUpper(f.Name, 0)
+	nullable := ""
+	if f.IsNullable() {
+		nullable = "Nullable"
+	}
+
+	switch {
+	case t.IsList() && !g.isAbstract(t):
+	case t.IsAttribute():
+		if f.IsNullable() {
+			return "EncodeNullable" + t.Name + "(" + name + ")"
+		}
+		return "Encode" + t.Name + "(&" + name + ")"
+	case isAnyAttribute(t):
+		return "Encode" + nullable + "Attribute(" + name + ")"
+	case g.isAbstract(t):
+		return "Encode" + nullable + "AbstractElement(" + name + ")"
+	}
+	if f.IsNullable() {
+		return "EncodeNullableElement(" + name + ")"
+	}
+	return "EncodeElement(&" + name + ")"
+}
+
+// decodeField writes the decoding of a field into the composite being
+// decoded
+func (g *Generator) decodeField(buf *bytes.Buffer, imp imports, f Field, self string) {
+	t := f.Type()
+	field := "composite." + charsToUpper(f.Name, 0)
+	name := f.Name
+	if token.IsKeyword(name) {
+		name += "_"
+	}
+	nullable := ""
+	if f.IsNullable() {
+		nullable = "Nullable"
+	}
+
+	switch {
+	case t.IsList() && !g.isAbstract(t):
+		g.decodeElement(buf, imp, f, t, name, field, self)
+		return
+	case t.IsAttribute():
+		buf.WriteString("\t" + name + ", err := decoder.Decode" + nullable + t.Name + "()\n")
+	case isAnyAttribute(t):
+		buf.WriteString("\t" + name + ", err := decoder.Decode" + nullable + "Attribute()\n")
+	case g.isAbstract(t):
+		buf.WriteString("\telement" + charsToUpper(f.Name, 0) + ", err := decoder.Decode" + nullable + "AbstractElement()\n")
+	default:
+		g.decodeElement(buf, imp, f, t, name, field, self)
+		return
+	}
+	buf.WriteString("\tif err != nil {\n")
+	buf.WriteString("\t\treturn nil, err\n")
+	buf.WriteString("\t}\n")
+
+	switch {
+	case t.IsAttribute() && !f.IsNullable():
+		buf.WriteString("\t" + field + " = *" + name + "\n")
+	case t.IsAttribute(), isAnyAttribute(t):
+		buf.WriteString("\t" + field + " = " + name + "\n")
+	default:
+		buf.WriteString("\t" + field + ", _ = element" + charsToUpper(f.Name, 0) + ".(" + g.goType(t, imp, self) + ")\n")
+	}
+}
+
+// decodeElement writes the decoding of a field which is a concrete
+// composite, an enumeration or a list
+func (g *Generator) decodeElement(buf *bytes.Buffer, imp imports, f Field, t Type, name string, field string, self string) {
+	goType := g.goType(t, imp, self)
+	null := goType[:strings.LastIndex(goType, ".")+1] + "Null" + t.AdaptType()
+	nullable := ""
+	if f.IsNullable() {
+		nullable = "Nullable"
+	}
+
+	buf.WriteString("\t" + name + ", err := decoder.Decode" + nullable + "Element(" + null + ")\n")
+	buf.WriteString("\tif err != nil {\n")
+	buf.WriteString("\t\treturn nil, err\n")
+	buf.WriteString("\t}\n")
+	if f.IsNullable() {
+		buf.WriteString("\t" + field + ", _ = " + name + ".(*" + goType + ")\n")
+	} else {
+		buf.WriteString("\t" + field + " = *" + name + ".(*" + goType + ")\n")
+	}
+}
+
+// abstractList writes the list of an abstract composite as an interface,
+// the lists of its concrete subtypes implement it
+func (g *Generator) abstractList(buf *bytes.Buffer, imp imports, c Composite, self string) {
+	imp.add("mal", malImport)
+	list := c.Name + "List"
+
+	buf.WriteString("\n// " + list + " is a list of the abstract composite " + c.Name + ", it is\n")
+	buf.WriteString("// one of the lists of its subtypes\n")
+	buf.WriteString("type " + list + " interface {\n")
+	buf.WriteString("\tmal.ElementList\n")
+	for _, parent := range g.ancestors(c) {
+		if parent.IsAbstract() {
+			buf.WriteString("\t" + g.compositeType(parent, imp, self) + "List\n")
+		}
+	}
+	buf.WriteString("\t" + list + "() " + list + "\n")
+	buf.WriteString("}\n")
+}
+
+// list writes the list type of a concrete composite or an enumeration,
+// its short form part is the negated one of the type. It implements the
+// lists of the abstract ancestors of the type.
+func (g *Generator) list(buf *bytes.Buffer, imp imports, name string, ancestors []ancestor, file dataFile) {
+	imp.add("mal", malImport)
+	list := name + "List"
+	constant := upperSnakeCase(name) + "_LIST"
+	entry := "*" + name
+
+	buf.WriteString("\n// " + list + " is a list of " + name + ", its entries can be NULL\n")
+	buf.WriteString("type " + list + " []" + entry + "\n")
+	g.writeShortForms(buf, list, constant, Type{Name: name, Area: file.area, Service: file.service, List: "true"})
+
+	buf.WriteString("\n// Null" + list + " is the NULL value of the " + list + " type\n")
+	buf.WriteString("var Null" + list + " *" + list + " = nil\n")
+
+	buf.WriteString("\n// New" + list + " creates a list of size NULL entries\n")
+	buf.WriteString("func New" + list + "(size int) *" + list + " {\n")
+	buf.WriteString("\tlist := " + list + "(make([]" + entry + ", size))\n")
+	buf.WriteString("\treturn &list\n")
+	buf.WriteString("}\n")
+
+	// Entries
+	buf.WriteString("\n// Size returns the number of entries of the list, -1 if it is NULL\n")
+	buf.WriteString("func (list *" + list + ") Size() int {\n")
+	buf.WriteString("\tif list == nil {\n")
+	buf.WriteString("\t\treturn -1\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString("\treturn len(*list)\n")
+	buf.WriteString("}\n")
+	buf.WriteString("\n// GetElementAt returns the entry at index i\n")
+	buf.WriteString("func (list *" + list + ") GetElementAt(i int) mal.Element {\n")
+	buf.WriteString("\tif list == nil || i < 0 || i >= len(*list) {\n")
+	buf.WriteString("\t\treturn nil\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString("\treturn (*list)[i]\n")
+	buf.WriteString("}\n")
+	buf.WriteString("\n// AppendElement adds an entry at the end of the list, a nil element\n")
+	buf.WriteString("// adds a NULL entry\n")
+	buf.WriteString("func (list *" + list + ") AppendElement(element mal.Element) {\n")
+	buf.WriteString("\tentry, _ := element.(" + entry + ")\n")
+	buf.WriteString("\t*list = append(*list, entry)\n")
+	buf.WriteString("}\n")
+
+	// Lists of the abstract ancestors implemented by the list
+	for _, parent := range ancestors {
+		if !parent.IsAbstract() {
+			continue
+		}
+		parentList := g.compositeType(parent, imp, file.self) + "List"
+		buf.WriteString("\n// " + parent.Name + "List implements the " + parentList + " interface\n")
+		buf.WriteString("func (list *" + list + ") " + parent.Name + "List() " + parentList + " {\n")
+		buf.WriteString("\treturn list\n")
+		buf.WriteString("}\n")
+	}
+
+	// Type identifiers
+	buf.WriteString("\n// Composite implements the mal.Composite interface\n")
+	buf.WriteString("func (list *" + list + ") Composite() mal.Composite {\n")
+	buf.WriteString("\treturn list\n")
+	buf.WriteString("}\n")
+	writeTypeIdentifiers(buf, list, constant)
+
+	// Element
+	buf.WriteString("\n// CreateElement creates an empty " + list + "\n")
+	buf.WriteString("func (*" + list + ") CreateElement() mal.Element {\n")
+	buf.WriteString("\treturn New" + list + "(0)\n")
+	buf.WriteString("}\n")
+	buf.WriteString("\n// IsNull checks if the " + list + " is NULL\n")
+	buf.WriteString("func (list *" + list + ") IsNull() bool {\n")
+	buf.WriteString("\treturn list == nil\n")
+	buf.WriteString("}\n")
+	buf.WriteString("\n// Null returns the NULL value of the " + list + " type\n")
+	buf.WriteString("func (*" + list + ") Null() mal.Element {\n")
+	buf.WriteString("\treturn Null" + list + "\n")
+	buf.WriteString("}\n")
+
+	// Encoding: the number of entries then the entries, which can be NULL
+	encode, decode := "EncodeNullableElement(entry)", "DecodeNullableElement(Null"+name+")"
+	buf.WriteString("\n// Encode encodes the size of the list then its entries\n")
+	buf.WriteString("func (list *" + list + ") Encode(encoder mal.Encoder) error {\n")
+	buf.WriteString("\tsize := mal.UInteger(len(*list))\n")
+	buf.WriteString("\terr := encoder.EncodeUInteger(&size)\n")
+	buf.WriteString("\tif err != nil {\n")
+	buf.WriteString("\t\treturn err\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString("\tfor _, entry := range *list {\n")
+	buf.WriteString("\t\terr = encoder." + encode + "\n")
+	buf.WriteString("\t\tif err != nil {\n")
+	buf.WriteString("\t\t\treturn err\n")
+	buf.WriteString("\t\t}\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString("\treturn nil\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// Decode decodes a " + list + "\n")
+	buf.WriteString("func (list *" + list + ") Decode(decoder mal.Decoder) (mal.Element, error) {\n")
+	buf.WriteString("\treturn Decode" + list + "(decoder)\n")
+	buf.WriteString("}\n")
+	buf.WriteString("\n// Decode" + list + " decodes the size of a " + list + " then its entries\n")
+	buf.WriteString("func Decode" + list + "(decoder mal.Decoder) (*" + list + ", error) {\n")
+	buf.WriteString("\tsize, err := decoder.DecodeUInteger()\n")
+	buf.WriteString("\tif err != nil {\n")
+	buf.WriteString("\t\treturn nil, err\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString("\tlist := New" + list + "(int(*size))\n")
+	buf.WriteString("\tfor i := range *list {\n")
+	buf.WriteString("\t\telement, err := decoder." + decode + "\n")
+	buf.WriteString("\t\tif err != nil {\n")
+	buf.WriteString("\t\t\treturn nil, err\n")
+	buf.WriteString("\t\t}\n")
+	buf.WriteString("\t\t(*list)[i], _ = element.(" + entry + ")\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString("\treturn list, nil\n")
+	buf.WriteString("}\n")
+}
+
+// writeTypeIdentifiers writes the methods identifying a generated type,
+// constant is the prefix of the constants holding its short forms
+func writeTypeIdentifiers(buf *bytes.Buffer, name string, constant string) {
+	identifiers := []struct{ method, result, value, comment string }{
+		{"GetShortForm", "mal.Long", constant + "_SHORT_FORM", "the absolute short form of the type"},
+		{"GetAreaNumber", "mal.UShort", "AREA_NUMBER", "the number of the area of the type"},
+		{"GetAreaVersion", "mal.UOctet", "AREA_VERSION", "the version of the area of the type"},
+		{"GetServiceNumber", "mal.UShort", "SERVICE_NUMBER", "the number of the service of the type"},
+		{"GetTypeShortForm", "mal.Integer", constant + "_TYPE_SHORT_FORM", "the short form part of the type"},
+	}
+	for _, id := range identifiers {
+		buf.WriteString("\n// " + id.method + " returns " + id.comment + "\n")
+		buf.WriteString("func (*" + name + ") " + id.method + "() " + id.result + " {\n")
+		buf.WriteString("\treturn " + id.value + "\n")
+		buf.WriteString("}\n")
+	}
+}
+
+// fieldType returns the Go type of a field: an interface if its type is
+// abstract, a pointer if it can be NULL and a value otherwise
+func (g *Generator) fieldType(f Field, imp imports, self string) string {
+	t := f.Type()
+	name := g.goType(t, imp, self)
+	if g.isAbstract(t) || !f.IsNullable() {
+		return name
+	}
+	return "*" + name
+}
+
+// isAbstract checks if a resolved type can't be instantiated, it is a
+// fundamental type, a composite without short form part of any area or
+// a list of one of them, as told by the area or the service defining it.
+// It is then used as an interface rather than a pointer in generated
+// code.
+func (g *Generator) isAbstract(t Type) bool {
+	for _, a := range g.allAreas() {
+		if a.Name != t.Area {
+			continue
+		}
+		if t.Service == "" {
+			return a.IsAbstractInArea(t.Name)
+		}
+		for _, s := range a.Services {
+			if s.Name == t.Service {
+				return s.IsAbstractInService(t.Name)
+			}
+		}
+	}
+	return false
+}
+
+// isAnyAttribute checks if a type is the abstract MAL::Attribute, its
+// values are encoded with their short form
+func isAnyAttribute(t Type) bool {
+	return t.IsFundamental() && t.Name == "Attribute" && !t.IsList()
+}
+
+// ancestor is a composite extended by another one
+type ancestor struct {
+	Composite
+	Type Type
+}
+
+// compositeType returns the name of an ancestor in generated code
+func (g *Generator) compositeType(a ancestor, imp imports, self string) string {
+	return g.goType(a.Type, imp, self)
+}
+
+// ancestors returns the composites extended by c, from its parent up
+// to the last composite before MAL::Composite
+func (g *Generator) ancestors(c Composite) []ancestor {
+	var res []ancestor
+	for _, t := range c.Extends {
+		parent, ok := g.LookupComposite(t.Area, t.Service, t.Name)
+		if !ok {
+			break
+		}
+		res = append(res, ancestor{parent, t})
+	}
+	return res
+}
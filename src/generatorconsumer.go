@@ -0,0 +1,413 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2018 CNES
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package src
+
+import (
+	"bytes"
+	"strings"
+)
+
+// createConsumer writes the consumer package of each service: a client
+// with a method per operation
+func (g *Generator) createConsumer(area Area) error {
+	filepath, err := g.areaPath(area)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range area.Services {
+		g.logf("> Consumer: %s", s.Name)
+
+		var body = new(bytes.Buffer)
+		imp := imports{}
+		imp.add("fmt", "fmt")
+		imp.add("mal", malImport)
+		imp.add("api", malAPIImport)
+
+		g.consumerStructure(body, s)
+		for _, op := range s.Operations {
+			if isPubSub(op) {
+				g.consumerSubscriber(body, imp, op, s, area)
+			} else {
+				g.consumerOperation(body, imp, op, s, area)
+			}
+		}
+		for _, op := range s.Operations {
+			if op.Pattern.Name == "progress" {
+				imp.add("errors", "errors")
+				body.WriteString("\n// ErrClosed is the error of a progress operation closed before its end\n")
+				body.WriteString("var ErrClosed = errors.New(\"progress closed\")\n")
+				break
+			}
+		}
+
+		var buffer = new(bytes.Buffer)
+		imp.write(buffer)
+		buffer.Write(body.Bytes())
+
+		serviceNameToLower := strings.ToLower(s.Name)
+		consumerfile := filepath + "/" + serviceNameToLower + "service/" + serviceNameToLower + "/consumer/consumer.go"
+		err = appendToFile(consumerfile, buffer)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// consumerStructure writes the client of a service and the error type
+// of the errors which are not raised by the operations
+func (g *Generator) consumerStructure(buf *bytes.Buffer, s Service) {
+	buf.WriteString("\n// Client is a consumer of the " + s.Name + " service\n")
+	buf.WriteString("type Client struct {\n")
+	buf.WriteString("\tcctx        *api.ClientContext\n")
+	buf.WriteString("\tproviderURI *mal.URI\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// NewClient creates a consumer named name in the MAL context, it calls\n")
+	buf.WriteString("// the provider of the " + s.Name + " service at providerURI\n")
+	buf.WriteString("func NewClient(ctx *mal.Context, name string, providerURI *mal.URI) (*Client, error) {\n")
+	buf.WriteString("\tcctx, err := api.NewClientContext(ctx, name)\n")
+	buf.WriteString("\tif err != nil {\n")
+	buf.WriteString("\t\treturn nil, err\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString("\treturn &Client{cctx, providerURI}, nil\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// Close closes the consumer\n")
+	buf.WriteString("func (c *Client) Close() error {\n")
+	buf.WriteString("\treturn c.cctx.Close()\n")
+	buf.WriteString("}\n")
+
+	errorType(buf, "provider")
+}
+
+// errorType writes the type of the errors returned by peer which are
+// not one of the errors of the operations
+func errorType(buf *bytes.Buffer, peer string) {
+	buf.WriteString("\n// Error is an error returned by the " + peer + " which is not one of the\n")
+	buf.WriteString("// errors of the operation, e.g. an error of the MAL\n")
+	buf.WriteString("type Error struct {\n")
+	buf.WriteString("\tnumber mal.UInteger\n")
+	buf.WriteString("\textra  mal.Element\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// Number returns the number of the error\n")
+	buf.WriteString("func (e *Error) Number() mal.UInteger {\n")
+	buf.WriteString("\treturn e.number\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// Extra returns the extra information of the error, nil if there is none\n")
+	buf.WriteString("func (e *Error) Extra() mal.Element {\n")
+	buf.WriteString("\treturn e.extra\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// Error implements the error interface\n")
+	buf.WriteString("func (e *Error) Error() string {\n")
+	buf.WriteString("\treturn fmt.Sprintf(\"error %d\", uint32(e.number))\n")
+	buf.WriteString("}\n")
+
+	writeIs(buf, "Error", "e.number")
+}
+
+// clientSignature returns the signature of the method of the client
+// calling an operation, it depends on its interaction pattern
+func (g *Generator) clientSignature(op Operation, in []parameter, out []parameter, imp imports) string {
+	signature := charsToUpper(op.Name, 0) + "(" + parameterList(in) + ")"
+	if op.Pattern.Name == "progress" {
+		return signature + " (*" + charsToUpper(op.Name, 0) + "Progress, error)"
+	}
+	return signature + " " + resultList(out)
+}
+
+// consumerOperation writes the method of the client calling an
+// operation: it encodes its parameters, performs the interaction and
+// decodes the response or the error
+func (g *Generator) consumerOperation(buf *bytes.Buffer, imp imports, op Operation, s Service, a Area) {
+	imp.add("cnst", g.constantsPackage(a, s))
+	pattern := charsToUpper(op.Pattern.Name, 0)
+	used := localNames("c", "op")
+	in := g.parameters(op.Pattern.Messages[0].Types, imp, used)
+	var ack, response []parameter
+	if op.Pattern.Name == "invoke" {
+		ack = g.parameters(op.Pattern.Message("ack").Types, imp, used)
+	}
+	switch op.Pattern.Name {
+	case "request", "invoke", "progress":
+		response = g.parameters(op.Pattern.Message("response").Types, imp, used)
+	}
+	// The invoke operations return their acknowledgement with their
+	// response
+	out := append(ack, response...)
+	fail := "return " + strings.Repeat("nil, ", len(out)) + "err"
+	failWith := func(err string) string {
+		return "return " + strings.Repeat("nil, ", len(out)) + err
+	}
+	operationError := charsToLower(op.Name, 0) + "Error(msg)"
+
+	buf.WriteString("\n")
+	writeComment(buf, "", docComment(op.Comment, charsToUpper(op.Name, 0)+" calls the "+op.Name+" "+op.Pattern.Name+" operation"))
+	buf.WriteString("func (c *Client) " + g.clientSignature(op, in, out, imp) + " {\n")
+	buf.WriteString("\top := c.cctx.New" + pattern + "Operation(c.providerURI, cnst." + areaNumber(s) + ", cnst." + areaVersion(s) + ", cnst." + serviceNumber(s) + ", cnst.OPERATION_IDENTIFIER_" + strings.ToUpper(op.Name) + ")\n")
+	if op.Pattern.Name != "progress" {
+		buf.WriteString("\tdefer op.Close()\n")
+	}
+	buf.WriteString("\tbody := op.NewBody()\n")
+	if op.Pattern.Name == "send" {
+		if len(in) != 0 {
+			buf.WriteString("\tvar err error\n")
+		}
+		writeEncodeParameters(buf, "\t", in, "body", fail)
+		buf.WriteString("\treturn op.Send(body)\n")
+		buf.WriteString("}\n")
+		return
+	}
+	if op.Pattern.Name == "progress" {
+		g.consumerProgress(buf, imp, op, in)
+		g.errorDecoder(buf, imp, op, s, a)
+		return
+	}
+	buf.WriteString("\tvar err error\n")
+	writeEncodeParameters(buf, "\t", in, "body", fail)
+
+	// Initiation of the interaction, acknowledged by the provider except
+	// for the request pattern
+	buf.WriteString("\tmsg, err := op." + pattern + "(body)\n")
+	buf.WriteString("\tif err != nil {\n")
+	buf.WriteString("\t\t" + fail + "\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString("\tif msg.IsErrorMessage {\n")
+	buf.WriteString("\t\t" + failWith(operationError) + "\n")
+	buf.WriteString("\t}\n")
+
+	if op.Pattern.Name == "invoke" {
+		writeDecodeParameters(buf, "\t", ack, "msg", fail)
+		buf.WriteString("\tmsg, err = op.GetResponse()\n")
+		buf.WriteString("\tif err != nil {\n")
+		buf.WriteString("\t\t" + fail + "\n")
+		buf.WriteString("\t}\n")
+		buf.WriteString("\tif msg.IsErrorMessage {\n")
+		buf.WriteString("\t\t" + failWith(operationError) + "\n")
+		buf.WriteString("\t}\n")
+	}
+
+	if len(ack) != 0 {
+		writeDecodeElements(buf, "\t", response, "msg", fail)
+	} else {
+		writeDecodeParameters(buf, "\t", response, "msg", fail)
+	}
+	buf.WriteString("\treturn " + joinParameters(parameterNames(out), "nil") + "\n")
+	buf.WriteString("}\n")
+
+	g.errorDecoder(buf, imp, op, s, a)
+}
+
+// consumerProgress writes the end of the method calling a progress
+// operation and the types receiving its updates and its response: once
+// acknowledged, the operation goes on in the background
+func (g *Generator) consumerProgress(buf *bytes.Buffer, imp imports, op Operation, in []parameter) {
+	imp.add("sync", "sync")
+	name := charsToUpper(op.Name, 0)
+	used := localNames("c", "op", "p", "progress", "updates", "done", "stop", "closing")
+	update := g.parameters(op.Pattern.Message("update").Types, imp, localNames("c", "op", "progress", "updates"))
+	ack := g.parameters(op.Pattern.Message("ack").Types, imp, used)
+	out := g.parameters(op.Pattern.Message("response").Types, imp, used)
+	fail := "return " + strings.Repeat("nil, ", len(out)) + "err"
+	operationError := charsToLower(op.Name, 0) + "Error(msg)"
+
+	// Initiation of the interaction
+	buf.WriteString("\tvar err error\n")
+	writeEncodeParameters(buf, "\t", in, "body", "op.Close()\n\t\treturn nil, err")
+	buf.WriteString("\tmsg, err := op.Progress(body)\n")
+	buf.WriteString("\tif err != nil {\n")
+	buf.WriteString("\t\top.Close()\n")
+	buf.WriteString("\t\treturn nil, err\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString("\tif msg.IsErrorMessage {\n")
+	buf.WriteString("\t\top.Close()\n")
+	buf.WriteString("\t\treturn nil, " + operationError + "\n")
+	buf.WriteString("\t}\n")
+	writeDecodeParameters(buf, "\t", ack, "msg", "op.Close()\n\t\treturn nil, err")
+
+	// Updates and response received in the background
+	buf.WriteString("\tupdates := make(chan *" + name + "Update)\n")
+	literal := "Updates: updates, done: make(chan struct{}), stop: make(chan struct{})"
+	for _, p := range ack {
+		literal += ", " + p.name + ": " + p.name
+	}
+	buf.WriteString("\tprogress := &" + name + "Progress{" + literal + "}\n")
+	buf.WriteString("\tgo func() {\n")
+	buf.WriteString("\t\tdefer op.Close()\n")
+	buf.WriteString("\t\tdefer close(progress.done)\n")
+	buf.WriteString("\t\tdefer close(updates)\n")
+	var results []string
+	for _, p := range out {
+		results = append(results, "progress."+p.name)
+	}
+	buf.WriteString("\t\t" + joinParameters(strings.Join(results, ", "), "progress.err") + " = func() " + resultList(out) + " {\n")
+	buf.WriteString("\t\t\tfor {\n")
+	buf.WriteString("\t\t\t\tmsg, err := op.GetUpdate()\n")
+	buf.WriteString("\t\t\t\tif err != nil {\n")
+	buf.WriteString("\t\t\t\t\t" + fail + "\n")
+	buf.WriteString("\t\t\t\t}\n")
+	buf.WriteString("\t\t\t\tif msg == nil {\n")
+	buf.WriteString("\t\t\t\t\tbreak\n")
+	buf.WriteString("\t\t\t\t}\n")
+	buf.WriteString("\t\t\t\tif msg.IsErrorMessage {\n")
+	buf.WriteString("\t\t\t\t\treturn " + strings.Repeat("nil, ", len(out)) + operationError + "\n")
+	buf.WriteString("\t\t\t\t}\n")
+	writeDecodeParameters(buf, "\t\t\t\t", update, "msg", fail)
+	buf.WriteString("\t\t\t\tselect {\n")
+	buf.WriteString("\t\t\t\tcase updates <- &" + name + "Update{" + parameterNames(update) + "}:\n")
+	buf.WriteString("\t\t\t\tcase <-progress.stop:\n")
+	buf.WriteString("\t\t\t\t\treturn " + strings.Repeat("nil, ", len(out)) + "ErrClosed\n")
+	buf.WriteString("\t\t\t\t}\n")
+	buf.WriteString("\t\t\t}\n")
+	buf.WriteString("\t\t\tmsg, err := op.GetResponse()\n")
+	buf.WriteString("\t\t\tif err != nil {\n")
+	buf.WriteString("\t\t\t\t" + fail + "\n")
+	buf.WriteString("\t\t\t}\n")
+	buf.WriteString("\t\t\tif msg.IsErrorMessage {\n")
+	buf.WriteString("\t\t\t\treturn " + strings.Repeat("nil, ", len(out)) + operationError + "\n")
+	buf.WriteString("\t\t\t}\n")
+	writeDecodeParameters(buf, "\t\t\t", out, "msg", fail)
+	buf.WriteString("\t\t\treturn " + joinParameters(parameterNames(out), "nil") + "\n")
+	buf.WriteString("\t\t}()\n")
+	buf.WriteString("\t}()\n")
+	buf.WriteString("\treturn progress, nil\n")
+	buf.WriteString("}\n")
+
+	// Update
+	buf.WriteString("\n// " + name + "Update is an update of the " + op.Name + " operation\n")
+	buf.WriteString("type " + name + "Update struct {\n")
+	width := 0
+	for _, p := range update {
+		if len(p.name) > width {
+			width = len(p.name)
+		}
+	}
+	for _, p := range update {
+		buf.WriteString("\t" + padRight(charsToUpper(p.name, 0), width) + " " + p.goType + "\n")
+	}
+	buf.WriteString("}\n")
+
+	// Progress
+	buf.WriteString("\n// " + name + "Progress is a " + op.Name + " operation in progress. Its updates\n")
+	buf.WriteString("// are received on Updates, which is closed at the end of the operation.\n")
+	buf.WriteString("// Close stops receiving them when they are not wanted anymore.\n")
+	buf.WriteString("type " + name + "Progress struct {\n")
+	width = len("Updates")
+	for _, p := range append(ack, out...) {
+		if len(p.name) > width {
+			width = len(p.name)
+		}
+	}
+	buf.WriteString("\t" + padRight("Updates", width) + " <-chan *" + name + "Update\n")
+	buf.WriteString("\t" + padRight("done", width) + " chan struct{}\n")
+	buf.WriteString("\t" + padRight("stop", width) + " chan struct{}\n")
+	buf.WriteString("\t" + padRight("closing", width) + " sync.Once\n")
+	for _, p := range append(ack, out...) {
+		buf.WriteString("\t" + padRight(p.name, width) + " " + p.goType + "\n")
+	}
+	buf.WriteString("\t" + padRight("err", width) + " error\n")
+	buf.WriteString("}\n")
+
+	if len(ack) != 0 {
+		results := parameterTypes(ack)
+		if len(ack) > 1 {
+			results = "(" + results + ")"
+		}
+		var fields []string
+		for _, p := range ack {
+			fields = append(fields, "p."+p.name)
+		}
+		buf.WriteString("\n// Acknowledgement returns the acknowledgement of the operation\n")
+		buf.WriteString("func (p *" + name + "Progress) Acknowledgement() " + results + " {\n")
+		buf.WriteString("\treturn " + strings.Join(fields, ", ") + "\n")
+		buf.WriteString("}\n")
+	}
+
+	buf.WriteString("\n// Close stops receiving the updates of the operation, Response returns\n")
+	buf.WriteString("// ErrClosed if an update was left unreceived\n")
+	buf.WriteString("func (p *" + name + "Progress) Close() {\n")
+	buf.WriteString("\tp.closing.Do(func() { close(p.stop) })\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// Response waits for the end of the operation and returns its response\n")
+	buf.WriteString("// or its error, the updates must be received meanwhile unless the\n")
+	buf.WriteString("// progress is closed\n")
+	buf.WriteString("func (p *" + name + "Progress) Response() " + resultList(out) + " {\n")
+	buf.WriteString("\t<-p.done\n")
+	var fields []string
+	for _, p := range out {
+		fields = append(fields, "p."+p.name)
+	}
+	buf.WriteString("\treturn " + joinParameters(strings.Join(fields, ", "), "p.err") + "\n")
+	buf.WriteString("}\n")
+}
+
+// errorDecoder writes the function decoding the error returned by an
+// operation, the errors of the operation get their generated type
+func (g *Generator) errorDecoder(buf *bytes.Buffer, imp imports, op Operation, s Service, a Area) {
+	name := charsToLower(op.Name, 0) + "Error"
+	errorsPackage, errorsPath := g.errorsPackage(a.Name, s.Name)
+
+	buf.WriteString("\n// " + name + " decodes the error returned by the " + op.Name + " operation\n")
+	buf.WriteString("func " + name + "(msg *mal.Message) error {\n")
+	buf.WriteString("\telement, err := msg.DecodeParameter(mal.NullUInteger)\n")
+	buf.WriteString("\tif err != nil {\n")
+	buf.WriteString("\t\treturn err\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString("\tnumber, ok := element.(*mal.UInteger)\n")
+	buf.WriteString("\tif !ok || number == nil {\n")
+	buf.WriteString("\t\treturn fmt.Errorf(\"" + op.Name + ": error without number\")\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString("\textra, err := msg.DecodeLastParameter(nil, true)\n")
+	buf.WriteString("\tif err != nil {\n")
+	buf.WriteString("\t\treturn err\n")
+	buf.WriteString("\t}\n")
+	if len(op.Errors) != 0 {
+		imp.add(errorsPackage, errorsPath)
+		buf.WriteString("\tswitch *number {\n")
+		for _, e := range op.Errors {
+			constructor := errorsPackage + ".New" + operationErrorName(op, e)
+			buf.WriteString("\tcase " + g.errorNumber(e, imp, "") + ":\n")
+			if e.HasExtraInformation() {
+				extraType := g.goType(e.ExtraInformation, imp, "")
+				if !g.isAbstract(e.ExtraInformation) {
+					extraType = "*" + extraType
+				}
+				buf.WriteString("\t\tinfo, _ := extra.(" + extraType + ")\n")
+				buf.WriteString("\t\treturn " + constructor + "(info)\n")
+			} else {
+				buf.WriteString("\t\treturn " + constructor + "()\n")
+			}
+		}
+		buf.WriteString("\t}\n")
+	}
+	buf.WriteString("\treturn &Error{*number, extra}\n")
+	buf.WriteString("}\n")
+}
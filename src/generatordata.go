@@ -24,6 +24,8 @@
 
 package src
 
+import "github.com/etiennelndr/archiveservice_generator/data"
+
 // Area TODO:
 type Area struct {
 	Name         string
@@ -31,10 +33,19 @@ type Area struct {
 	Version      string
 	Comment      string
 	Requirements string
-
-	Services   []Service
-	Composites []Composite
-	Errors     []Error
+	// Position of the area in the specification, the other elements
+	// have one as well, it is not part of the inspected model
+	Pos data.Position `json:"-"`
+	// Number and Version parsed when the model is built
+	number  uint16
+	version uint8
+
+	Services     []Service
+	Fundamentals []Fundamental
+	Attributes   []Attribute
+	Composites   []Composite
+	Enumerations []Enumeration
+	Errors       []Error
 }
 
 // CreateArea creates a new area and returns it
@@ -49,11 +60,26 @@ func CreateArea(name string, number string, version string, comment string, requ
 	return area
 }
 
+// AddFundamental adds a new fundamental type to the area
+func (a *Area) AddFundamental(f Fundamental) {
+	a.Fundamentals = append(a.Fundamentals, f)
+}
+
+// AddAttribute adds a new attribute type to the area
+func (a *Area) AddAttribute(at Attribute) {
+	a.Attributes = append(a.Attributes, at)
+}
+
 // AddComposite TODO:
 func (a *Area) AddComposite(c Composite) {
 	a.Composites = append(a.Composites, c)
 }
 
+// AddEnumeration adds a new enumeration type to the area
+func (a *Area) AddEnumeration(e Enumeration) {
+	a.Enumerations = append(a.Enumerations, e)
+}
+
 // AddError TODO:
 func (a *Area) AddError(e Error) {
 	a.Errors = append(a.Errors, e)
@@ -75,8 +101,12 @@ func (a Area) AbstractTypes() []Composite {
 	return composites
 }
 
-// IsAbstractInArea TODO:
+// IsAbstractInArea checks if data is an abstract type of this area: a
+// fundamental type or an abstract composite
 func (a Area) IsAbstractInArea(data string) bool {
+	if a.IsFundamentalInArea(data) {
+		return true
+	}
 	for _, c := range a.Composites {
 		if c.Name == data {
 			return c.IsAbstract()
@@ -85,15 +115,47 @@ func (a Area) IsAbstractInArea(data string) bool {
 	return false
 }
 
+// IsAttributeInArea checks if data is an attribute type of this area
+func (a Area) IsAttributeInArea(data string) bool {
+	for _, at := range a.Attributes {
+		if at.Name == data {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFundamentalInArea checks if data is a fundamental type of this area
+func (a Area) IsFundamentalInArea(data string) bool {
+	for _, f := range a.Fundamentals {
+		if f.Name == data {
+			return true
+		}
+	}
+	return false
+}
+
 // Service TODO:
 type Service struct {
 	Name    string
 	Number  string
 	Comment string
+	Pos     data.Position `json:"-"`
+	// Number parsed when the model is built
+	number uint16
 
 	Operations   []Operation
 	Composites   []Composite
 	Enumerations []Enumeration
+	Errors       []Error
+
+	// COM features
+	ObjectsComment string
+	Objects        []COMObject
+	EventsComment  string
+	Events         []COMObject
+	ArchiveUsage   *Usage
+	ActivityUsage  *Usage
 }
 
 // CreateService creates a new service and returns it
@@ -132,7 +194,23 @@ func (s *Service) AddEnumeration(data Enumeration) {
 	s.Enumerations = append(s.Enumerations, data)
 }
 
-// IsAbstractInService TODO:
+// AddError adds a new error to the service
+func (s *Service) AddError(e Error) {
+	s.Errors = append(s.Errors, e)
+}
+
+// AddObject adds a new COM object to the service
+func (s *Service) AddObject(o COMObject) {
+	s.Objects = append(s.Objects, o)
+}
+
+// AddEvent adds a new COM event to the service
+func (s *Service) AddEvent(e COMObject) {
+	s.Events = append(s.Events, e)
+}
+
+// IsAbstractInService checks if data is an abstract composite of this
+// service
 func (s Service) IsAbstractInService(data string) bool {
 	for _, c := range s.Composites {
 		if c.Name == data && c.IsAbstract() {
@@ -142,13 +220,56 @@ func (s Service) IsAbstractInService(data string) bool {
 	return false
 }
 
+// COMObject is an object or an event declared in the COM features of
+// a service
+type COMObject struct {
+	Name    string
+	Number  string
+	Comment string
+	Event   bool
+	Pos     data.Position `json:"-"`
+	// Type of the body of the object, its name is empty if the object
+	// has no body
+	ObjectType    Type
+	RelatedObject *ObjectReference
+	SourceObject  *ObjectReference
+}
+
+// HasBody checks if the object has a body
+func (o COMObject) HasBody() bool {
+	return o.ObjectType.Name != ""
+}
+
+// ObjectReference describes the related or the source object of a COM
+// object. Area, Service and Number are empty if any object can be
+// referenced.
+type ObjectReference struct {
+	Comment string
+	Area    string
+	Service string
+	Number  string
+}
+
+// Usage describes how a service uses the COM archive or activity
+// tracking
+type Usage struct {
+	Comment string
+}
+
 // Operation TODO:
 type Operation struct {
 	Name    string
 	Number  string
 	Comment string
+	Pos     data.Position `json:"-"`
 
 	Pattern PatternInteraction
+	Errors  []OperationError
+}
+
+// AddError adds a new error which can be raised by the operation
+func (op *Operation) AddError(e OperationError) {
+	op.Errors = append(op.Errors, e)
 }
 
 // PatternInteraction TODO:
@@ -162,6 +283,17 @@ func (p *PatternInteraction) AddMessage(m Message) {
 	p.Messages = append(p.Messages, m)
 }
 
+// Message returns the message of the pattern with this name, e.g.
+// response, it has no types if the pattern has no such message
+func (p PatternInteraction) Message(name string) Message {
+	for _, m := range p.Messages {
+		if m.Name == name {
+			return m
+		}
+	}
+	return Message{Name: name}
+}
+
 // Message TODO:
 type Message struct {
 	Name  string
@@ -181,6 +313,21 @@ type Type struct {
 	List          string
 	Service       string
 	Area          string
+	Pos           data.Position `json:"-"`
+	// Kind of the definition of this type, set by Generator.Validate
+	Kind string
+}
+
+// IsFundamental checks if the type is one of the abstract base types
+// Element, Attribute or Composite. The type must have been resolved.
+func (t Type) IsFundamental() bool {
+	return t.Kind == KindFundamental
+}
+
+// IsAttribute checks if the type is an attribute type, e.g. Boolean.
+// The type must have been resolved.
+func (t Type) IsAttribute() bool {
+	return t.Kind == KindAttribute
 }
 
 // IsList checks if the type is a list or not
@@ -188,6 +335,19 @@ func (t Type) IsList() bool {
 	return t.List == "true"
 }
 
+// String returns the qualified name of the type, e.g. COM::Archive::ArchiveDetails
+func (t Type) String() string {
+	name := t.Area + "::"
+	if t.Service != "" {
+		name += t.Service + "::"
+	}
+	name += t.Name
+	if t.IsList() {
+		name += "List"
+	}
+	return name
+}
+
 // AdaptType is useful to retrieve the real type. Indeed, if
 // this type is a list it returns the name of the type + 'List'.
 // Otherwise only the name is returned.
@@ -198,17 +358,46 @@ func (t Type) AdaptType() string {
 	return t.Name
 }
 
+// Fundamental is one of the abstract base types of the MAL: Element,
+// Attribute or Composite
+type Fundamental struct {
+	Name    string
+	Comment string
+	Pos     data.Position `json:"-"`
+	// Extends
+	NameOfTypeToExtend string
+	AreaOfTypeToExtend string
+}
+
+// Attribute is a MAL attribute type, e.g. Boolean or Identifier
+type Attribute struct {
+	Name          string
+	ShortFormPart string
+	Comment       string
+	Pos           data.Position `json:"-"`
+	// ShortFormPart parsed when the model is built
+	shortFormPart int32
+}
+
 // Composite TODO:
 type Composite struct {
 	Name          string
 	ShortFormPart string
 	Comment       string
-	isAbstract    bool
+	Pos           data.Position `json:"-"`
+	// ShortFormPart parsed when the model is built
+	shortFormPart int32
 	// Fields
 	Fields []Field
 	// Extends
-	NameOfTypeToExtend string
-	AreaOfTypeToExtend string
+	NameOfTypeToExtend    string
+	AreaOfTypeToExtend    string
+	ServiceOfTypeToExtend string
+	// Extends is the chain of the types extended by the composite, from
+	// its parent up to MAL::Composite, set by Generator.Validate
+	Extends []Type
+	// Fields of the composites extended, in encoding order
+	inherited []Field
 }
 
 // NewComposite create a new composite
@@ -229,14 +418,21 @@ func (c *Composite) AddField(f Field) {
 	c.Fields = append(c.Fields, f)
 }
 
-// IsAbstract TODO:
-func (c Composite) IsAbstract() bool {
-	return c.isAbstract
+// AllFields returns the fields of the composite in encoding order: the
+// ones of the composites it extends first, from the top of the
+// hierarchy, then its own fields. The inherited fields are only known
+// once the model has been validated.
+func (c Composite) AllFields() []Field {
+	fields := append([]Field(nil), c.inherited...)
+	return append(fields, c.Fields...)
 }
 
-// MakeAbstract TODO:
-func (c *Composite) MakeAbstract() {
-	c.isAbstract = true
+// IsAbstract checks if the composite is abstract: it has no short form
+// part, so it can't be encoded and only its concrete subtypes can be
+// used, e.g. COM::Archive::QueryFilter. A concrete composite may have
+// no fields.
+func (c Composite) IsAbstract() bool {
+	return c.ShortFormPart == ""
 }
 
 // Field TODO:
@@ -244,9 +440,31 @@ type Field struct {
 	Name      string
 	CanBeNull string
 	Comment   string
+	Pos       data.Position `json:"-"`
 	// Type
-	TypeName string
-	TypeArea string
+	TypeName    string
+	TypeArea    string
+	TypeService string
+	TypeList    string
+	TypeKind    string
+}
+
+// IsNullable checks if the field can be NULL, which is the default
+// when canBeNull is not given
+func (f Field) IsNullable() bool {
+	return f.CanBeNull != "false"
+}
+
+// Type returns the type of the field
+func (f Field) Type() Type {
+	return Type{
+		Name:    f.TypeName,
+		Area:    f.TypeArea,
+		Service: f.TypeService,
+		List:    f.TypeList,
+		Kind:    f.TypeKind,
+		Pos:     f.Pos,
+	}
 }
 
 // Enumeration TODO:
@@ -254,7 +472,10 @@ type Enumeration struct {
 	Name          string
 	ShortFormPart string
 	Comment       string
+	Pos           data.Position `json:"-"`
 	Items         []Item
+	// ShortFormPart parsed when the model is built
+	shortFormPart int32
 }
 
 // AddItem adds a new item to the enumeration
@@ -267,6 +488,7 @@ type Item struct {
 	Value   string
 	NValue  string
 	Comment string
+	Pos     data.Position `json:"-"`
 }
 
 // Error TODO:
@@ -274,4 +496,34 @@ type Error struct {
 	Name    string
 	Number  string
 	Comment string
+	Pos     data.Position `json:"-"`
+	// Extra information returned with the error
+	ExtraInformation Type
+	ExtraComment     string
+}
+
+// HasExtraInformation checks if extra information is returned with
+// the error
+func (e Error) HasExtraInformation() bool {
+	return e.ExtraInformation.Name != ""
+}
+
+// OperationError is an error which can be raised by an operation. It
+// is either defined by the operation or a reference to an error of an
+// area or a service, in which case Number is set once the reference
+// has been resolved.
+type OperationError struct {
+	Error
+	Area      string
+	Service   string
+	Reference bool
+}
+
+// String returns the qualified name of the error, e.g. COM::INVALID
+func (e OperationError) String() string {
+	name := e.Area + "::"
+	if e.Service != "" {
+		name += e.Service + "::"
+	}
+	return name + e.Name
 }
@@ -0,0 +1,174 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2018 CNES
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package src
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// enumeration writes an enumeration as a named type whose value is the
+// ordinal of the item, its numeric values are given by NumericValue
+func (g *Generator) enumeration(buf *bytes.Buffer, imp imports, e Enumeration, file dataFile) {
+	imp.add("mal", malImport)
+	imp.add("fmt", "fmt")
+	constant := upperSnakeCase(e.Name)
+	ordinal := enumerationOrdinalType(e)
+
+	buf.WriteString("\n")
+	t := Type{Name: e.Name, Area: file.area, Service: file.service}
+	writeComment(buf, "", docComment(e.Comment, e.Name+" is the "+t.String()+" enumeration"))
+	buf.WriteString("type " + e.Name + " uint32\n")
+
+	// Items
+	buf.WriteString("\n// Items of the " + e.Name + " enumeration\n")
+	buf.WriteString("const (\n")
+	for i, item := range e.Items {
+		writeComment(buf, "\t", item.Comment)
+		buf.WriteString("\t" + enumerationItem(e, item) + " " + e.Name + " = " + strconv.Itoa(i) + "\n")
+	}
+	buf.WriteString(")\n")
+
+	g.writeShortForms(buf, e.Name, constant, t)
+
+	buf.WriteString("\n// Null" + e.Name + " is the NULL value of the " + e.Name + " type\n")
+	buf.WriteString("var Null" + e.Name + " *" + e.Name + " = nil\n")
+
+	// Values
+	values := "numericValues" + e.Name
+	names := "names" + e.Name
+	buf.WriteString("\n// " + values + " holds the numeric values of the items, indexed by\n")
+	buf.WriteString("// their ordinal\n")
+	buf.WriteString("var " + values + " = []mal.UInteger{\n")
+	for _, item := range e.Items {
+		buf.WriteString("\t" + item.NValue + ",\n")
+	}
+	buf.WriteString("}\n")
+	buf.WriteString("\n// " + names + " holds the names of the items, indexed by their ordinal\n")
+	buf.WriteString("var " + names + " = []string{\n")
+	for _, item := range e.Items {
+		buf.WriteString("\t\"" + item.Value + "\",\n")
+	}
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// Ordinal returns the position of the item in the enumeration\n")
+	buf.WriteString("func (e " + e.Name + ") Ordinal() mal.UInteger {\n")
+	buf.WriteString("\treturn mal.UInteger(e)\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// NumericValue returns the numeric value of the item, or the maximum\n")
+	buf.WriteString("// UInteger if e is not an item of the enumeration\n")
+	buf.WriteString("func (e " + e.Name + ") NumericValue() mal.UInteger {\n")
+	buf.WriteString("\tif int(e) >= len(" + values + ") {\n")
+	buf.WriteString("\t\treturn ^mal.UInteger(0)\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString("\treturn " + values + "[e]\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// " + e.Name + "FromNumericValue returns the item whose numeric value is value\n")
+	buf.WriteString("func " + e.Name + "FromNumericValue(value mal.UInteger) (" + e.Name + ", error) {\n")
+	buf.WriteString("\tfor i, v := range " + values + " {\n")
+	buf.WriteString("\t\tif v == value {\n")
+	buf.WriteString("\t\t\treturn " + e.Name + "(i), nil\n")
+	buf.WriteString("\t\t}\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString("\treturn 0, fmt.Errorf(\"unknown " + e.Name + " numeric value %d\", value)\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// String returns the name of the item\n")
+	buf.WriteString("func (e " + e.Name + ") String() string {\n")
+	buf.WriteString("\tif int(e) >= len(" + names + ") {\n")
+	buf.WriteString("\t\treturn fmt.Sprintf(\"" + e.Name + "(%d)\", uint32(e))\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString("\treturn " + names + "[e]\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// Parse" + e.Name + " returns the item whose name is s\n")
+	buf.WriteString("func Parse" + e.Name + "(s string) (" + e.Name + ", error) {\n")
+	buf.WriteString("\tfor i, name := range " + names + " {\n")
+	buf.WriteString("\t\tif name == s {\n")
+	buf.WriteString("\t\t\treturn " + e.Name + "(i), nil\n")
+	buf.WriteString("\t\t}\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString("\treturn 0, fmt.Errorf(\"unknown " + e.Name + " %q\", s)\n")
+	buf.WriteString("}\n")
+
+	// Element
+	writeTypeIdentifiers(buf, e.Name, constant)
+	buf.WriteString("\n// CreateElement creates a " + e.Name + "\n")
+	buf.WriteString("func (*" + e.Name + ") CreateElement() mal.Element {\n")
+	buf.WriteString("\treturn new(" + e.Name + ")\n")
+	buf.WriteString("}\n")
+	buf.WriteString("\n// IsNull checks if the " + e.Name + " is NULL\n")
+	buf.WriteString("func (e *" + e.Name + ") IsNull() bool {\n")
+	buf.WriteString("\treturn e == nil\n")
+	buf.WriteString("}\n")
+	buf.WriteString("\n// Null returns the NULL value of the " + e.Name + " type\n")
+	buf.WriteString("func (*" + e.Name + ") Null() mal.Element {\n")
+	buf.WriteString("\treturn Null" + e.Name + "\n")
+	buf.WriteString("}\n")
+
+	// Encoding: the ordinal in the smallest type holding all the items
+	buf.WriteString("\n// Encode encodes the ordinal of the item as a mal." + ordinal + "\n")
+	buf.WriteString("func (e *" + e.Name + ") Encode(encoder mal.Encoder) error {\n")
+	buf.WriteString("\tordinal := mal." + ordinal + "(*e)\n")
+	buf.WriteString("\treturn encoder.Encode" + ordinal + "(&ordinal)\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// Decode decodes a " + e.Name + "\n")
+	buf.WriteString("func (e *" + e.Name + ") Decode(decoder mal.Decoder) (mal.Element, error) {\n")
+	buf.WriteString("\treturn Decode" + e.Name + "(decoder)\n")
+	buf.WriteString("}\n")
+	buf.WriteString("\n// Decode" + e.Name + " decodes the ordinal of a " + e.Name + "\n")
+	buf.WriteString("func Decode" + e.Name + "(decoder mal.Decoder) (*" + e.Name + ", error) {\n")
+	buf.WriteString("\tordinal, err := decoder.Decode" + ordinal + "()\n")
+	buf.WriteString("\tif err != nil {\n")
+	buf.WriteString("\t\treturn nil, err\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString("\tif int(*ordinal) >= len(" + names + ") {\n")
+	buf.WriteString("\t\treturn nil, fmt.Errorf(\"unknown " + e.Name + " ordinal %d\", *ordinal)\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString("\te := " + e.Name + "(*ordinal)\n")
+	buf.WriteString("\treturn &e, nil\n")
+	buf.WriteString("}\n")
+}
+
+// enumerationItem returns the name of the constant of an item, e.g.
+// EXPRESSION_OPERATOR_EQUAL
+func enumerationItem(e Enumeration, item Item) string {
+	return upperSnakeCase(e.Name) + "_" + item.Value
+}
+
+// enumerationOrdinalType returns the MAL attribute encoding the ordinal
+// of an enumeration, the smallest one holding all its items
+func enumerationOrdinalType(e Enumeration) string {
+	switch {
+	case len(e.Items) <= 1<<8:
+		return "UOctet"
+	case len(e.Items) <= 1<<16:
+		return "UShort"
+	}
+	return "UInteger"
+}
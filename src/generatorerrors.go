@@ -0,0 +1,259 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2018 CNES
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package src
+
+import (
+	"bytes"
+	"strings"
+)
+
+// createErrors writes the errors of the area in its errors package and,
+// in the errors package of each service, the errors of the service and
+// the ones raised by its operations
+func (g *Generator) createErrors(area Area) error {
+	filepath, err := g.areaPath(area)
+	if err != nil {
+		return err
+	}
+
+	if len(area.Errors) != 0 {
+		var buffer = new(bytes.Buffer)
+		imp := imports{}
+		var body = new(bytes.Buffer)
+		g.errors(body, imp, area.Errors, area.Name, "the "+area.Name+" area")
+		imp.write(buffer)
+		buffer.Write(body.Bytes())
+		err = appendToFile(filepath+"/errors/errors.go", buffer)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, s := range area.Services {
+		var body = new(bytes.Buffer)
+		imp := imports{}
+		if len(s.Errors) != 0 {
+			g.errors(body, imp, s.Errors, area.Name+"::"+s.Name, "the "+s.Name+" service")
+		}
+		for _, op := range s.Operations {
+			for _, e := range op.Errors {
+				g.operationError(body, imp, op, e, s, area)
+			}
+		}
+		if body.Len() == 0 {
+			continue
+		}
+
+		var buffer = new(bytes.Buffer)
+		imp.write(buffer)
+		buffer.Write(body.Bytes())
+
+		serviceNameToLower := strings.ToLower(s.Name)
+		errorsfile := filepath + "/" + serviceNameToLower + "service/errors/errors.go"
+		err = appendToFile(errorsfile, buffer)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// errors writes the errors of an area or a service: their numbers, the
+// Error type raised with them and a sentinel value of each error to be
+// used with errors.Is. prefix qualifies the names of the errors, e.g.
+// COM, and owner describes where they are defined.
+func (g *Generator) errors(buf *bytes.Buffer, imp imports, errs []Error, prefix string, owner string) {
+	imp.add("mal", malImport)
+	imp.add("fmt", "fmt")
+
+	// Numbers
+	buf.WriteString("\n// ErrorNumber is the number of an error of " + owner + "\n")
+	buf.WriteString("type ErrorNumber mal.UInteger\n")
+	buf.WriteString("\n// Numbers of the errors of " + owner + "\n")
+	buf.WriteString("const (\n")
+	for _, e := range errs {
+		g.logf("> Error: %s", e.Name)
+		writeComment(buf, "\t", e.Comment)
+		buf.WriteString("\t" + e.Name + " ErrorNumber = " + e.Number + "\n")
+	}
+	buf.WriteString(")\n")
+
+	buf.WriteString("\n// String returns the qualified name of the error, e.g. " + prefix + "::" + errs[0].Name + "\n")
+	buf.WriteString("func (n ErrorNumber) String() string {\n")
+	buf.WriteString("\tswitch n {\n")
+	for _, e := range errs {
+		buf.WriteString("\tcase " + e.Name + ":\n")
+		buf.WriteString("\t\treturn \"" + prefix + "::" + e.Name + "\"\n")
+	}
+	buf.WriteString("\t}\n")
+	buf.WriteString("\treturn fmt.Sprintf(\"ErrorNumber(%d)\", uint32(n))\n")
+	buf.WriteString("}\n")
+
+	// Error
+	buf.WriteString("\n// Error is an error of " + owner + " raised with its extra information\n")
+	buf.WriteString("type Error struct {\n")
+	buf.WriteString("\tnumber           ErrorNumber\n")
+	buf.WriteString("\tExtraInformation mal.Element\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// NewError creates an error, extraInformation is nil if there is none\n")
+	buf.WriteString("func NewError(number ErrorNumber, extraInformation mal.Element) *Error {\n")
+	buf.WriteString("\treturn &Error{number, extraInformation}\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// Number returns the number of the error\n")
+	buf.WriteString("func (e *Error) Number() mal.UInteger {\n")
+	buf.WriteString("\treturn mal.UInteger(e.number)\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// Extra returns the extra information of the error, nil if there is none\n")
+	buf.WriteString("func (e *Error) Extra() mal.Element {\n")
+	buf.WriteString("\treturn e.ExtraInformation\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// Body returns the body of the MAL error message: the number of the\n")
+	buf.WriteString("// error and its extra information\n")
+	buf.WriteString("func (e *Error) Body() (*mal.UInteger, mal.Element) {\n")
+	buf.WriteString("\tnumber := e.Number()\n")
+	buf.WriteString("\treturn &number, e.ExtraInformation\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// Error implements the error interface\n")
+	buf.WriteString("func (e *Error) Error() string {\n")
+	buf.WriteString("\treturn fmt.Sprintf(\"%s (%d)\", e.number, uint32(e.number))\n")
+	buf.WriteString("}\n")
+
+	writeIs(buf, "Error", "e.Number()")
+
+	// Sentinels
+	buf.WriteString("\n// Errors of " + owner + ", to be compared with errors.Is\n")
+	buf.WriteString("var (\n")
+	width := 0
+	for _, e := range errs {
+		if len(errorSentinel(e)) > width {
+			width = len(errorSentinel(e))
+		}
+	}
+	for _, e := range errs {
+		buf.WriteString("\t" + padRight(errorSentinel(e), width) + " = &Error{number: " + e.Name + "}\n")
+	}
+	buf.WriteString(")\n")
+
+	buf.WriteString("\n// LookupError returns the sentinel of the error number\n")
+	buf.WriteString("func LookupError(number mal.UInteger) (*Error, bool) {\n")
+	buf.WriteString("\tswitch ErrorNumber(number) {\n")
+	for _, e := range errs {
+		buf.WriteString("\tcase " + e.Name + ":\n")
+		buf.WriteString("\t\treturn " + errorSentinel(e) + ", true\n")
+	}
+	buf.WriteString("\t}\n")
+	buf.WriteString("\treturn nil, false\n")
+	buf.WriteString("}\n")
+}
+
+// errorSentinel returns the name of the sentinel value of an error, e.g.
+// ErrInvalid
+func errorSentinel(e Error) string {
+	return "Err" + camelCase(e.Name)
+}
+
+// writeIs writes the Is method of an error type, which matches any error
+// with the same number, e.g. the sentinel of the area of the error
+func writeIs(buf *bytes.Buffer, name string, number string) {
+	buf.WriteString("\n// Is reports whether target is an error with the same number\n")
+	buf.WriteString("func (e *" + name + ") Is(target error) bool {\n")
+	buf.WriteString("\tt, ok := target.(interface{ Number() mal.UInteger })\n")
+	buf.WriteString("\treturn ok && t.Number() == " + number + "\n")
+	buf.WriteString("}\n")
+}
+
+// operationErrorName returns the name of the Go type of an error raised
+// by an operation, e.g. RetrieveUnknownError
+func operationErrorName(op Operation, e OperationError) string {
+	return charsToUpper(op.Name, 0) + camelCase(e.Name) + "Error"
+}
+
+// operationError writes the type of an error raised by an operation,
+// its extra information is typed after the specification
+func (g *Generator) operationError(buf *bytes.Buffer, imp imports, op Operation, e OperationError, s Service, a Area) {
+	name := operationErrorName(op, e)
+	imp.add("mal", malImport)
+	_, self := g.errorsPackage(a.Name, s.Name)
+	number := g.errorNumber(e, imp, self)
+
+	// Structure
+	buf.WriteString("\n")
+	writeComment(buf, "", name+" is the "+e.String()+" error raised by the "+op.Name+" operation. "+e.Comment)
+	buf.WriteString("type " + name + " struct {\n")
+	extraType := ""
+	if e.HasExtraInformation() {
+		extraType = g.goType(e.ExtraInformation, imp, "")
+		if !g.isAbstract(e.ExtraInformation) {
+			extraType = "*" + extraType
+		}
+		writeComment(buf, "\t", e.ExtraComment)
+		buf.WriteString("\tExtraInformation " + extraType + "\n")
+	}
+	buf.WriteString("}\n")
+
+	// Constructor
+	buf.WriteString("\n// New" + name + " creates a new " + e.String() + " error for the " + op.Name + " operation\n")
+	if extraType != "" {
+		buf.WriteString("func New" + name + "(extraInformation " + extraType + ") *" + name + " {\n")
+		buf.WriteString("\treturn &" + name + "{ExtraInformation: extraInformation}\n")
+	} else {
+		buf.WriteString("func New" + name + "() *" + name + " {\n")
+		buf.WriteString("\treturn &" + name + "{}\n")
+	}
+	buf.WriteString("}\n")
+
+	// Number
+	buf.WriteString("\n// Number returns the number of the " + e.String() + " error\n")
+	buf.WriteString("func (e *" + name + ") Number() mal.UInteger {\n")
+	buf.WriteString("\treturn " + number + "\n")
+	buf.WriteString("}\n")
+
+	// Extra information
+	buf.WriteString("\n// Extra returns the extra information of the error, nil if there is none\n")
+	buf.WriteString("func (e *" + name + ") Extra() mal.Element {\n")
+	if extraType != "" {
+		buf.WriteString("\tif e.ExtraInformation == nil {\n")
+		buf.WriteString("\t\treturn nil\n")
+		buf.WriteString("\t}\n")
+		buf.WriteString("\treturn e.ExtraInformation\n")
+	} else {
+		buf.WriteString("\treturn nil\n")
+	}
+	buf.WriteString("}\n")
+
+	// error interface
+	buf.WriteString("\n// Error implements the error interface\n")
+	buf.WriteString("func (e *" + name + ") Error() string {\n")
+	buf.WriteString("\treturn \"" + op.Name + ": " + e.String() + " (" + e.Number + ")\"\n")
+	buf.WriteString("}\n")
+
+	writeIs(buf, name, number)
+}
@@ -0,0 +1,179 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2018 CNES
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package src
+
+import (
+	"bytes"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+)
+
+// malAPIImport is the package of the MAL API used by the providers and
+// the consumers
+const malAPIImport = "github.com/ccsdsmo/malgo/mal/api"
+
+// parameter is a parameter of the body of a MAL message in generated
+// code. Its value is a pointer, or an interface if its type is
+// abstract, so that it can be NULL.
+type parameter struct {
+	name     string
+	goType   string
+	null     string
+	abstract bool
+}
+
+// parameters returns the parameters of the types of a message, their
+// names are made unique with the ones already used
+func (g *Generator) parameters(types []Type, imp imports, used map[string]bool) []parameter {
+	var params []parameter
+	for _, t := range types {
+		p := parameter{
+			name:     uniqueName(charsToLower(t.AdaptType(), 0), used),
+			abstract: g.isAbstract(t),
+		}
+		goType := g.goType(t, imp, "")
+		if p.abstract {
+			p.goType = goType
+			p.null = "nil"
+		} else {
+			p.goType = "*" + goType
+			p.null = goType[:strings.LastIndex(goType, ".")+1] + "Null" + t.AdaptType()
+		}
+		params = append(params, p)
+	}
+	return params
+}
+
+// uniqueName returns name, followed by a number if it is already used,
+// and marks it as used. The keywords and the predeclared identifiers of
+// Go, e.g. string, get the Value suffix.
+func uniqueName(name string, used map[string]bool) string {
+	if token.IsKeyword(name) || types.Universe.Lookup(name) != nil {
+		name += "Value"
+	}
+	unique := name
+	for i := 2; used[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	used[unique] = true
+	return unique
+}
+
+// localNames returns the set of the names of the local variables of the
+// generated functions, the parameters can't use them
+func localNames(names ...string) map[string]bool {
+	used := map[string]bool{"err": true, "element": true, "body": true, "msg": true}
+	for _, name := range names {
+		used[name] = true
+	}
+	return used
+}
+
+// parameterList returns the declaration of parameters, e.g.
+// "objectType *com.ObjectType, longList *mal.LongList"
+func parameterList(params []parameter) string {
+	var decls []string
+	for _, p := range params {
+		decls = append(decls, p.name+" "+p.goType)
+	}
+	return strings.Join(decls, ", ")
+}
+
+// parameterNames returns the names of parameters separated by commas
+func parameterNames(params []parameter) string {
+	var names []string
+	for _, p := range params {
+		names = append(names, p.name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// parameterTypes returns the types of parameters separated by commas
+func parameterTypes(params []parameter) string {
+	var types []string
+	for _, p := range params {
+		types = append(types, p.goType)
+	}
+	return strings.Join(types, ", ")
+}
+
+// resultList returns the results of a function returning parameters and
+// an error, e.g. "error" or "(*mal.Long, error)"
+func resultList(params []parameter) string {
+	if len(params) == 0 {
+		return "error"
+	}
+	return "(" + parameterTypes(params) + ", error)"
+}
+
+// nilArguments returns the arguments passing NULL to parameters, e.g.
+// "nil, nil"
+func nilArguments(params []parameter) string {
+	return strings.TrimSuffix(strings.Repeat("nil, ", len(params)), ", ")
+}
+
+// writeDecodeParameters writes the decoding of parameters from the body
+// of a message, err must be declared and fail is the statement
+// returning it
+func writeDecodeParameters(buf *bytes.Buffer, indent string, params []parameter, msg string, fail string) {
+	if len(params) == 0 {
+		return
+	}
+	buf.WriteString(indent + "var element mal.Element\n")
+	writeDecodeElements(buf, indent, params, msg, fail)
+}
+
+// writeDecodeElements writes the decoding of parameters like
+// writeDecodeParameters, element must be declared as well
+func writeDecodeElements(buf *bytes.Buffer, indent string, params []parameter, msg string, fail string) {
+	for i, p := range params {
+		if i+1 == len(params) {
+			buf.WriteString(indent + "element, err = " + msg + ".DecodeLastParameter(" + p.null + ", " + strconv.FormatBool(p.abstract) + ")\n")
+		} else {
+			buf.WriteString(indent + "element, err = " + msg + ".DecodeParameter(" + p.null + ")\n")
+		}
+		buf.WriteString(indent + "if err != nil {\n")
+		buf.WriteString(indent + "\t" + fail + "\n")
+		buf.WriteString(indent + "}\n")
+		buf.WriteString(indent + p.name + ", _ := element.(" + p.goType + ")\n")
+	}
+}
+
+// writeEncodeParameters writes the encoding of parameters in the body of
+// a message, fail is the statement returning err
+func writeEncodeParameters(buf *bytes.Buffer, indent string, params []parameter, body string, fail string) {
+	for i, p := range params {
+		if i+1 == len(params) {
+			buf.WriteString(indent + "err = " + body + ".EncodeLastParameter(" + p.name + ", " + strconv.FormatBool(p.abstract) + ")\n")
+		} else {
+			buf.WriteString(indent + "err = " + body + ".EncodeParameter(" + p.name + ")\n")
+		}
+		buf.WriteString(indent + "if err != nil {\n")
+		buf.WriteString(indent + "\t" + fail + "\n")
+		buf.WriteString(indent + "}\n")
+	}
+}
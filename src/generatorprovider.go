@@ -0,0 +1,355 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2018 CNES
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package src
+
+import (
+	"bytes"
+	"strings"
+)
+
+// createProvider writes the provider package of each service: the
+// handler of its operations and the provider calling it
+func (g *Generator) createProvider(area Area) error {
+	filepath, err := g.areaPath(area)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range area.Services {
+		g.logf("> Provider: %s", s.Name)
+
+		var body = new(bytes.Buffer)
+		imp := imports{}
+		imp.add("errors", "errors")
+		imp.add("mal", malImport)
+		imp.add("api", malAPIImport)
+
+		g.providerHandler(body, imp, s)
+		g.providerStructure(body, imp, s, area)
+		for _, op := range s.Operations {
+			if op.Pattern.Name == "progress" {
+				g.providerUpdater(body, imp, op)
+			}
+			if isPubSub(op) {
+				g.providerPublisher(body, imp, op, s, area)
+			} else {
+				g.providerOperation(body, imp, op)
+			}
+		}
+		for _, op := range s.Operations {
+			if isPubSub(op) {
+				errorType(body, "broker")
+				break
+			}
+		}
+
+		var buffer = new(bytes.Buffer)
+		imp.write(buffer)
+		buffer.Write(body.Bytes())
+
+		serviceNameToLower := strings.ToLower(s.Name)
+		providerfile := filepath + "/" + serviceNameToLower + "service/" + serviceNameToLower + "/provider/provider.go"
+		err = appendToFile(providerfile, buffer)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// constantsPackage returns the import path of the constants package of
+// a service
+func (g *Generator) constantsPackage(a Area, s Service) string {
+	serviceNameToLower := strings.ToLower(s.Name)
+	return g.areaModulePath(a) + "/" + serviceNameToLower + "service/" + serviceNameToLower + "/constants"
+}
+
+// hasHandler checks if an operation is handled by the provider, the
+// updates of the publish-subscribe operations are published by the
+// provider instead
+func hasHandler(op Operation) bool {
+	return !isPubSub(op)
+}
+
+// handlerSignature returns the signature of the method of the handler
+// of an operation, it depends on its interaction pattern
+func (g *Generator) handlerSignature(op Operation, imp imports) string {
+	used := map[string]bool{}
+	in := g.parameters(op.Pattern.Messages[0].Types, imp, used)
+	args := parameterList(in)
+
+	var ack, results []parameter
+	switch op.Pattern.Name {
+	case "request", "invoke", "progress":
+		ack = g.parameters(op.Pattern.Message("ack").Types, imp, used)
+		results = g.parameters(op.Pattern.Message("response").Types, imp, used)
+	}
+
+	// Acknowledgement and updates sent by the handler
+	switch op.Pattern.Name {
+	case "invoke", "progress":
+		args = joinParameters(args, "acknowledge "+acknowledgeType(ack))
+	}
+	if op.Pattern.Name == "progress" {
+		args = joinParameters(args, "updater *"+charsToUpper(op.Name, 0)+"Updater")
+	}
+
+	signature := charsToUpper(op.Name, 0) + "(" + args + ")"
+	if op.Pattern.Name == "send" {
+		return signature
+	}
+	return signature + " " + resultList(results)
+}
+
+// acknowledgeType returns the type of the function acknowledging an
+// operation with the parameters of its acknowledgement
+func acknowledgeType(ack []parameter) string {
+	return "func(" + parameterList(ack) + ") error"
+}
+
+// joinParameters adds a parameter to a list of parameters
+func joinParameters(params string, param string) string {
+	if params == "" {
+		return param
+	}
+	return params + ", " + param
+}
+
+// providerHandler writes the interface implemented by the handler of
+// the operations of a service
+func (g *Generator) providerHandler(buf *bytes.Buffer, imp imports, s Service) {
+	buf.WriteString("\n// Handler handles the operations of the " + s.Name + " service. An error\n")
+	buf.WriteString("// returned by a method is sent to the consumer, with its number if it\n")
+	buf.WriteString("// has a Number method like the generated errors, as MAL::INTERNAL\n")
+	buf.WriteString("// otherwise. The invoke and progress operations acknowledge the\n")
+	buf.WriteString("// interaction before sending their response, the progress operations\n")
+	buf.WriteString("// send their updates with their updater.\n")
+	buf.WriteString("type Handler interface {\n")
+	first := true
+	for _, op := range s.Operations {
+		if !hasHandler(op) {
+			continue
+		}
+		if !first {
+			buf.WriteString("\n")
+		}
+		first = false
+		writeComment(buf, "\t", docComment(op.Comment, charsToUpper(op.Name, 0)+" handles the "+op.Name+" "+op.Pattern.Name+" operation"))
+		buf.WriteString("\t" + g.handlerSignature(op, imp) + "\n")
+	}
+	buf.WriteString("}\n")
+}
+
+// providerStructure writes the provider of a service, which registers
+// the handler of each operation
+func (g *Generator) providerStructure(buf *bytes.Buffer, imp imports, s Service, a Area) {
+	// The errors returned without number are sent as MAL::INTERNAL
+	internal := g.errorNumber(OperationError{Error: Error{Name: "INTERNAL"}, Area: malArea, Reference: true}, imp, "")
+
+	buf.WriteString("\n// Provider is a provider of the " + s.Name + " service, it calls its handler\n")
+	buf.WriteString("// for each operation received\n")
+	buf.WriteString("type Provider struct {\n")
+	buf.WriteString("\tcctx    *api.ClientContext\n")
+	buf.WriteString("\thandler Handler\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// NewProvider creates a provider of the " + s.Name + " service named name in\n")
+	buf.WriteString("// the MAL context and registers the handler of its operations\n")
+	buf.WriteString("func NewProvider(ctx *mal.Context, name string, handler Handler) (*Provider, error) {\n")
+	buf.WriteString("\tcctx, err := api.NewClientContext(ctx, name)\n")
+	buf.WriteString("\tif err != nil {\n")
+	buf.WriteString("\t\treturn nil, err\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString("\tp := &Provider{cctx, handler}\n")
+	for _, op := range s.Operations {
+		if !hasHandler(op) {
+			continue
+		}
+		imp.add("cnst", g.constantsPackage(a, s))
+		buf.WriteString("\terr = cctx.Register" + charsToUpper(op.Pattern.Name, 0) + "Handler(cnst." + areaNumber(s) + ", cnst." + areaVersion(s) + ", cnst." + serviceNumber(s) + ", cnst.OPERATION_IDENTIFIER_" + strings.ToUpper(op.Name) + ", p." + charsToLower(op.Name, 0) + ")\n")
+		buf.WriteString("\tif err != nil {\n")
+		buf.WriteString("\t\tcctx.Close()\n")
+		buf.WriteString("\t\treturn nil, err\n")
+		buf.WriteString("\t}\n")
+	}
+	buf.WriteString("\treturn p, nil\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// URI returns the URI of the provider\n")
+	buf.WriteString("func (p *Provider) URI() *mal.URI {\n")
+	buf.WriteString("\treturn p.cctx.Uri\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// Close stops the provider\n")
+	buf.WriteString("func (p *Provider) Close() error {\n")
+	buf.WriteString("\treturn p.cctx.Close()\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// numbered is an error sent with its number and extra information\n")
+	buf.WriteString("type numbered interface {\n")
+	buf.WriteString("\tNumber() mal.UInteger\n")
+	buf.WriteString("\tExtra() mal.Element\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// sendError sends an error returned by the handler with its number and\n")
+	buf.WriteString("// extra information, send is the Ack or the Reply of the transaction\n")
+	buf.WriteString("func sendError(send func(mal.Body, bool) error, transaction api.Transaction, cause error) error {\n")
+	buf.WriteString("\tnumber := " + internal + "\n")
+	buf.WriteString("\tvar extra mal.Element = mal.NewString(cause.Error())\n")
+	buf.WriteString("\tvar e numbered\n")
+	buf.WriteString("\tif errors.As(cause, &e) {\n")
+	buf.WriteString("\t\tnumber, extra = e.Number(), e.Extra()\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString("\tbody := transaction.NewBody()\n")
+	buf.WriteString("\terr := body.EncodeParameter(&number)\n")
+	buf.WriteString("\tif err != nil {\n")
+	buf.WriteString("\t\treturn err\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString("\terr = body.EncodeLastParameter(extra, true)\n")
+	buf.WriteString("\tif err != nil {\n")
+	buf.WriteString("\t\treturn err\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString("\treturn send(body, true)\n")
+	buf.WriteString("}\n")
+}
+
+// providerOperation writes the function receiving an operation: it
+// decodes its parameters, calls the handler and sends its results
+func (g *Generator) providerOperation(buf *bytes.Buffer, imp imports, op Operation) {
+	if !hasHandler(op) {
+		return
+	}
+	pattern := charsToUpper(op.Pattern.Name, 0)
+	used := localNames("p", "t", "transaction", "acked", "acknowledge", "updater")
+	in := g.parameters(op.Pattern.Messages[0].Types, imp, used)
+	ack := g.parameters(op.Pattern.Message("ack").Types, imp, used)
+	out := g.parameters(op.Pattern.Message("response").Types, imp, used)
+	acknowledged := op.Pattern.Name == "invoke" || op.Pattern.Name == "progress"
+
+	buf.WriteString("\n// " + charsToLower(op.Name, 0) + " receives the " + op.Name + " operation\n")
+	buf.WriteString("func (p *Provider) " + charsToLower(op.Name, 0) + "(msg *mal.Message, t api.Transaction) error {\n")
+	if op.Pattern.Name != "send" {
+		buf.WriteString("\ttransaction := t.(api." + pattern + "Transaction)\n")
+	}
+	if len(in) != 0 || op.Pattern.Name != "send" {
+		buf.WriteString("\tvar err error\n")
+	}
+	writeDecodeParameters(buf, "\t", in, "msg", "return err")
+
+	args := parameterNames(in)
+	if acknowledged {
+		// The acknowledgement is sent once, by the handler or before
+		// the response
+		buf.WriteString("\tacked := false\n")
+		buf.WriteString("\tacknowledge := " + acknowledgeType(ack) + " {\n")
+		buf.WriteString("\t\tif acked {\n")
+		buf.WriteString("\t\t\treturn nil\n")
+		buf.WriteString("\t\t}\n")
+		buf.WriteString("\t\tacked = true\n")
+		if len(ack) == 0 {
+			buf.WriteString("\t\treturn transaction.Ack(nil, false)\n")
+		} else {
+			buf.WriteString("\t\tbody := transaction.NewBody()\n")
+			buf.WriteString("\t\tvar err error\n")
+			writeEncodeParameters(buf, "\t\t", ack, "body", "return err")
+			buf.WriteString("\t\treturn transaction.Ack(body, false)\n")
+		}
+		buf.WriteString("\t}\n")
+		args = joinParameters(args, "acknowledge")
+	}
+	if op.Pattern.Name == "progress" {
+		buf.WriteString("\tupdater := &" + charsToUpper(op.Name, 0) + "Updater{transaction, acknowledge}\n")
+		args = joinParameters(args, "updater")
+	}
+	call := "p.handler." + charsToUpper(op.Name, 0) + "(" + args + ")"
+
+	switch op.Pattern.Name {
+	case "send":
+		buf.WriteString("\t" + call + "\n")
+		buf.WriteString("\treturn nil\n")
+		buf.WriteString("}\n")
+		return
+	case "submit":
+		buf.WriteString("\terr = " + call + "\n")
+		buf.WriteString("\tif err != nil {\n")
+		buf.WriteString("\t\treturn sendError(transaction.Ack, transaction, err)\n")
+		buf.WriteString("\t}\n")
+		buf.WriteString("\treturn transaction.Ack(nil, false)\n")
+		buf.WriteString("}\n")
+		return
+	}
+
+	if len(out) != 0 {
+		buf.WriteString("\t" + parameterNames(out) + ", err := " + call + "\n")
+	} else {
+		buf.WriteString("\terr = " + call + "\n")
+	}
+	buf.WriteString("\tif err != nil {\n")
+	if acknowledged {
+		buf.WriteString("\t\tif !acked {\n")
+		buf.WriteString("\t\t\tacked = true\n")
+		buf.WriteString("\t\t\treturn sendError(transaction.Ack, transaction, err)\n")
+		buf.WriteString("\t\t}\n")
+	}
+	buf.WriteString("\t\treturn sendError(transaction.Reply, transaction, err)\n")
+	buf.WriteString("\t}\n")
+	if acknowledged {
+		buf.WriteString("\terr = acknowledge(" + nilArguments(ack) + ")\n")
+		buf.WriteString("\tif err != nil {\n")
+		buf.WriteString("\t\treturn err\n")
+		buf.WriteString("\t}\n")
+	}
+	buf.WriteString("\tbody := transaction.NewBody()\n")
+	writeEncodeParameters(buf, "\t", out, "body", "return err")
+	buf.WriteString("\treturn transaction.Reply(body, false)\n")
+	buf.WriteString("}\n")
+}
+
+// providerUpdater writes the type sending the updates of a progress
+// operation, the handler gets it to send its updates
+func (g *Generator) providerUpdater(buf *bytes.Buffer, imp imports, op Operation) {
+	name := charsToUpper(op.Name, 0) + "Updater"
+	update := g.parameters(op.Pattern.Message("update").Types, imp, localNames("u"))
+	ack := g.parameters(op.Pattern.Message("ack").Types, imp, localNames("u"))
+
+	buf.WriteString("\n// " + name + " sends the updates of the " + op.Name + " operation\n")
+	buf.WriteString("type " + name + " struct {\n")
+	buf.WriteString("\ttransaction api.ProgressTransaction\n")
+	buf.WriteString("\tacknowledge " + acknowledgeType(ack) + "\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// Update sends an update to the consumer, the operation is acknowledged\n")
+	buf.WriteString("// first, without acknowledgement parameters, if it is not yet\n")
+	buf.WriteString("func (u *" + name + ") Update(" + parameterList(update) + ") error {\n")
+	buf.WriteString("\terr := u.acknowledge(" + nilArguments(ack) + ")\n")
+	buf.WriteString("\tif err != nil {\n")
+	buf.WriteString("\t\treturn err\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString("\tbody := u.transaction.NewBody()\n")
+	writeEncodeParameters(buf, "\t", update, "body", "return err")
+	buf.WriteString("\treturn u.transaction.Update(body, false)\n")
+	buf.WriteString("}\n")
+}
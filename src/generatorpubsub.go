@@ -0,0 +1,208 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2018 CNES
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package src
+
+import (
+	"bytes"
+	"strings"
+)
+
+// isPubSub checks if an operation uses the publish-subscribe pattern
+func isPubSub(op Operation) bool {
+	return op.Pattern.Name == "pubsub"
+}
+
+// publishedTypes returns the types of the lists published by a
+// publish-subscribe operation, one per type of its publishNotify
+// message
+func publishedTypes(op Operation) []Type {
+	var types []Type
+	for _, t := range op.Pattern.Message("publishNotify").Types {
+		t.List = "true"
+		types = append(types, t)
+	}
+	return types
+}
+
+// malParameter returns the parameter of a type of the MAL area with its
+// own name
+func (g *Generator) malParameter(name string, t Type, imp imports) parameter {
+	p := g.parameters([]Type{t}, imp, map[string]bool{})[0]
+	p.name = name
+	return p
+}
+
+// pubSubCall writes the creation of a publish-subscribe operation of a
+// service with the broker at brokerURI
+func pubSubCall(kind string, cctx string, op Operation, s Service) string {
+	return cctx + ".New" + kind + "Operation(brokerURI, cnst." + areaNumber(s) + ", cnst." + areaVersion(s) + ", cnst." + serviceNumber(s) + ", cnst.OPERATION_IDENTIFIER_" + strings.ToUpper(op.Name) + ")"
+}
+
+// writeBrokerCall writes the call of the broker by an operation, the
+// error returned by the broker is decoded with the error of the
+// operation
+func writeBrokerCall(buf *bytes.Buffer, op Operation, call string) {
+	buf.WriteString("\tmsg, err := " + call + "\n")
+	buf.WriteString("\tif err != nil {\n")
+	buf.WriteString("\t\treturn err\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString("\tif msg.IsErrorMessage {\n")
+	buf.WriteString("\t\treturn " + charsToLower(op.Name, 0) + "Error(msg)\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString("\treturn nil\n")
+	buf.WriteString("}\n")
+}
+
+// providerPublisher writes the publisher of a publish-subscribe
+// operation, created by the provider of the service
+func (g *Generator) providerPublisher(buf *bytes.Buffer, imp imports, op Operation, s Service, a Area) {
+	imp.add("fmt", "fmt")
+	imp.add("cnst", g.constantsPackage(a, s))
+	name := charsToUpper(op.Name, 0) + "Publisher"
+	entityKeys := g.malParameter("entityKeys", Type{Area: malArea, Name: "EntityKey", List: "true"}, imp)
+	updateHeaders := g.malParameter("updateHeaders", Type{Area: malArea, Name: "UpdateHeader", List: "true"}, imp)
+	published := g.parameters(publishedTypes(op), imp, localNames("pub", "updateHeaders"))
+	published = append([]parameter{updateHeaders}, published...)
+
+	buf.WriteString("\n")
+	writeComment(buf, "", name+" publishes the updates of the "+op.Name+" operation. "+op.Comment)
+	buf.WriteString("type " + name + " struct {\n")
+	buf.WriteString("\top api.PublisherOperation\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// New" + name + " creates a publisher of the " + op.Name + " operation to\n")
+	buf.WriteString("// the broker at brokerURI\n")
+	buf.WriteString("func (p *Provider) New" + name + "(brokerURI *mal.URI) *" + name + " {\n")
+	buf.WriteString("\treturn &" + name + "{" + pubSubCall("Publisher", "p.cctx", op, s) + "}\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// Register registers the publisher with the broker for the entity keys\n")
+	buf.WriteString("func (pub *" + name + ") Register(" + parameterList([]parameter{entityKeys}) + ") error {\n")
+	buf.WriteString("\tbody := pub.op.NewBody()\n")
+	buf.WriteString("\terr := body.EncodeLastParameter(entityKeys, false)\n")
+	buf.WriteString("\tif err != nil {\n")
+	buf.WriteString("\t\treturn err\n")
+	buf.WriteString("\t}\n")
+	writeBrokerCall(buf, op, "pub.op.Register(body)")
+
+	buf.WriteString("\n// Publish publishes updates, each of them has a header and an element\n")
+	buf.WriteString("// in each list\n")
+	buf.WriteString("func (pub *" + name + ") Publish(" + parameterList(published) + ") error {\n")
+	buf.WriteString("\tbody := pub.op.NewBody()\n")
+	buf.WriteString("\tvar err error\n")
+	writeEncodeParameters(buf, "\t", published, "body", "return err")
+	buf.WriteString("\treturn pub.op.Publish(body)\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// Deregister deregisters the publisher from the broker\n")
+	buf.WriteString("func (pub *" + name + ") Deregister() error {\n")
+	writeBrokerCall(buf, op, "pub.op.Deregister(pub.op.NewBody())")
+
+	buf.WriteString("\n// Close closes the publisher\n")
+	buf.WriteString("func (pub *" + name + ") Close() error {\n")
+	buf.WriteString("\treturn pub.op.Close()\n")
+	buf.WriteString("}\n")
+
+	g.errorDecoder(buf, imp, op, s, a)
+}
+
+// consumerSubscriber writes the subscriber of a publish-subscribe
+// operation, created by the client of the service, and the type of the
+// function called for each of its notifications
+func (g *Generator) consumerSubscriber(buf *bytes.Buffer, imp imports, op Operation, s Service, a Area) {
+	imp.add("cnst", g.constantsPackage(a, s))
+	name := charsToUpper(op.Name, 0) + "Subscriber"
+	notifyName := charsToUpper(op.Name, 0) + "Notify"
+	subscription := g.malParameter("subscription", Type{Area: malArea, Name: "Subscription"}, imp)
+	subscriptionIDs := g.malParameter("subscriptionIDs", Type{Area: malArea, Name: "Identifier", List: "true"}, imp)
+	subscriptionID := g.malParameter("subscriptionID", Type{Area: malArea, Name: "Identifier"}, imp)
+	updateHeaders := g.malParameter("updateHeaders", Type{Area: malArea, Name: "UpdateHeader", List: "true"}, imp)
+	notified := g.parameters(publishedTypes(op), imp, localNames("sub", "notify", "subscriptionID", "updateHeaders"))
+	notified = append([]parameter{subscriptionID, updateHeaders}, notified...)
+
+	buf.WriteString("\n")
+	writeComment(buf, "", name+" receives the updates of the "+op.Name+" operation. "+op.Comment)
+	buf.WriteString("type " + name + " struct {\n")
+	buf.WriteString("\top api.SubscriberOperation\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// " + notifyName + " is called for each notification of the " + op.Name + "\n")
+	buf.WriteString("// operation with the identifier of the subscription and the updates,\n")
+	buf.WriteString("// each of them has a header and an element in each list\n")
+	buf.WriteString("type " + notifyName + " func(" + parameterList(notified) + ") error\n")
+
+	buf.WriteString("\n// New" + name + " creates a subscriber to the " + op.Name + " operation\n")
+	buf.WriteString("// with the broker at brokerURI\n")
+	buf.WriteString("func (c *Client) New" + name + "(brokerURI *mal.URI) *" + name + " {\n")
+	buf.WriteString("\treturn &" + name + "{" + pubSubCall("Subscriber", "c.cctx", op, s) + "}\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// Register registers the subscription with the broker\n")
+	buf.WriteString("func (sub *" + name + ") Register(" + parameterList([]parameter{subscription}) + ") error {\n")
+	buf.WriteString("\tbody := sub.op.NewBody()\n")
+	buf.WriteString("\terr := body.EncodeLastParameter(subscription, false)\n")
+	buf.WriteString("\tif err != nil {\n")
+	buf.WriteString("\t\treturn err\n")
+	buf.WriteString("\t}\n")
+	writeBrokerCall(buf, op, "sub.op.Register(body)")
+
+	buf.WriteString("\n// Listen receives the notifications and calls notify for each of them,\n")
+	buf.WriteString("// until the subscriber is closed or notify returns an error\n")
+	buf.WriteString("func (sub *" + name + ") Listen(notify " + notifyName + ") error {\n")
+	buf.WriteString("\tfor {\n")
+	buf.WriteString("\t\tmsg, err := sub.op.GetNotify()\n")
+	buf.WriteString("\t\tif err != nil {\n")
+	buf.WriteString("\t\t\treturn err\n")
+	buf.WriteString("\t\t}\n")
+	buf.WriteString("\t\tif msg == nil {\n")
+	buf.WriteString("\t\t\treturn nil\n")
+	buf.WriteString("\t\t}\n")
+	buf.WriteString("\t\tif msg.IsErrorMessage {\n")
+	buf.WriteString("\t\t\treturn " + charsToLower(op.Name, 0) + "Error(msg)\n")
+	buf.WriteString("\t\t}\n")
+	writeDecodeParameters(buf, "\t\t", notified, "msg", "return err")
+	buf.WriteString("\t\terr = notify(" + parameterNames(notified) + ")\n")
+	buf.WriteString("\t\tif err != nil {\n")
+	buf.WriteString("\t\t\treturn err\n")
+	buf.WriteString("\t\t}\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString("}\n")
+
+	buf.WriteString("\n// Deregister deregisters the subscriptions from the broker\n")
+	buf.WriteString("func (sub *" + name + ") Deregister(" + parameterList([]parameter{subscriptionIDs}) + ") error {\n")
+	buf.WriteString("\tbody := sub.op.NewBody()\n")
+	buf.WriteString("\terr := body.EncodeLastParameter(subscriptionIDs, false)\n")
+	buf.WriteString("\tif err != nil {\n")
+	buf.WriteString("\t\treturn err\n")
+	buf.WriteString("\t}\n")
+	writeBrokerCall(buf, op, "sub.op.Deregister(body)")
+
+	buf.WriteString("\n// Close closes the subscriber, Listen returns once it is closed\n")
+	buf.WriteString("func (sub *" + name + ") Close() error {\n")
+	buf.WriteString("\treturn sub.op.Close()\n")
+	buf.WriteString("}\n")
+
+	g.errorDecoder(buf, imp, op, s, a)
+}
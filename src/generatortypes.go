@@ -0,0 +1,209 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2018 CNES
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package src
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"sort"
+	"strings"
+)
+
+// malArea is the name of the MAL area, its types are the ones of the
+// MAL API
+const (
+	malArea   = "MAL"
+	malImport = "github.com/ccsdsmo/malgo/mal"
+)
+
+// imports is the set of packages imported by a generated file, indexed
+// by their alias
+type imports map[string]string
+
+func (imp imports) add(alias string, path string) {
+	imp[alias] = path
+}
+
+// write writes the import block sorted by path, the packages of the
+// standard library first like goimports
+func (imp imports) write(buf *bytes.Buffer) {
+	if len(imp) == 0 {
+		return
+	}
+
+	var aliases []string
+	for alias := range imp {
+		aliases = append(aliases, alias)
+	}
+	sort.Slice(aliases, func(i, j int) bool {
+		left, right := imp[aliases[i]], imp[aliases[j]]
+		if isStandard(left) != isStandard(right) {
+			return isStandard(left)
+		}
+		return left < right
+	})
+
+	buf.WriteString("\nimport (\n")
+	for i, alias := range aliases {
+		path := imp[alias]
+		if i != 0 && isStandard(imp[aliases[i-1]]) && !isStandard(path) {
+			buf.WriteString("\n")
+		}
+		if path[strings.LastIndex(path, "/")+1:] == alias {
+			buf.WriteString("\t\"" + path + "\"\n")
+		} else {
+			buf.WriteString("\t" + alias + " \"" + path + "\"\n")
+		}
+	}
+	buf.WriteString(")\n")
+}
+
+// isStandard checks if an import path is a package of the standard
+// library, their first element has no dot
+func isStandard(path string) bool {
+	return !strings.Contains(strings.SplitN(path, "/", 2)[0], ".")
+}
+
+// dataPackage returns the alias and the import path of the package
+// holding the data types of an area or, if service is given, of one of
+// its services
+func (g *Generator) dataPackage(area string, service string) (string, string) {
+	if area == malArea {
+		return "mal", malImport
+	}
+	path := g.modulePath() + "/" + strings.ToLower(area)
+	if service == "" {
+		return strings.ToLower(area), path + "/data"
+	}
+	return strings.ToLower(service), path + "/" + strings.ToLower(service) + "service/data"
+}
+
+// errorsPackage returns the alias and the import path of the errors
+// package of an area or, if service is given, of one of its services
+func (g *Generator) errorsPackage(area string, service string) (string, string) {
+	path := g.modulePath() + "/" + strings.ToLower(area)
+	if service == "" {
+		return strings.ToLower(area) + "errors", path + "/errors"
+	}
+	return strings.ToLower(service) + "errors", path + "/" + strings.ToLower(service) + "service/errors"
+}
+
+// errorNumber returns the number of an error raised by an operation in
+// generated code: the constant of the errors package defining it, or
+// its number if the operation defines it. It adds the package to the
+// imports, self is the import path of the generated file.
+func (g *Generator) errorNumber(e OperationError, imp imports, self string) string {
+	if !e.Reference {
+		return e.Number
+	}
+	alias, path := g.errorsPackage(e.Area, e.Service)
+	if path == self {
+		return "mal.UInteger(" + e.Name + ")"
+	}
+	imp.add(alias, path)
+	return "mal.UInteger(" + alias + "." + e.Name + ")"
+}
+
+// goType returns the name of a type in generated code and adds its
+// package to the imports, self is the import path of the generated
+// file which needs no qualifier
+func (g *Generator) goType(t Type, imp imports, self string) string {
+	alias, path := g.dataPackage(t.Area, t.Service)
+	if path == self {
+		return t.AdaptType()
+	}
+	imp.add(alias, path)
+	return alias + "." + t.AdaptType()
+}
+
+// camelCase converts a MAL constant name to a Go name, e.g.
+// INCORRECT_STATE becomes IncorrectState
+func camelCase(name string) string {
+	var res string
+	for _, part := range strings.Split(strings.ToLower(name), "_") {
+		if part != "" {
+			res += strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return res
+}
+
+// padRight adds spaces at the end of a name to align the declarations
+// of a block
+func padRight(name string, width int) string {
+	return name + strings.Repeat(" ", width-len(name))
+}
+
+// writeComment writes a comment of the specification wrapped on several
+// lines, each line starts with indent
+func writeComment(buf *bytes.Buffer, indent string, comment string) {
+	line := ""
+	for _, word := range strings.Fields(comment) {
+		if line != "" && len(line)+len(word) >= 72 {
+			buf.WriteString(indent + "//" + line + "\n")
+			line = ""
+		}
+		line += " " + word
+	}
+	if line != "" {
+		buf.WriteString(indent + "//" + line + "\n")
+	}
+}
+
+// docComment returns the doc comment of a generated declaration: the
+// comment of the specification or, if it has none, summary, followed by
+// the sentences given
+func docComment(comment string, summary string, sentences ...string) string {
+	if strings.TrimSpace(comment) == "" {
+		comment = summary
+	}
+	var parts []string
+	for _, s := range append([]string{comment}, sentences...) {
+		if s = strings.TrimSpace(s); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	for i := 0; i+1 < len(parts); i++ {
+		parts[i] = strings.TrimSuffix(parts[i], ".")
+	}
+	return strings.Join(parts, ". ")
+}
+
+// appendToFile appends the content of a buffer to an existing file and
+// formats the result like gofmt
+func appendToFile(path string, buf *bytes.Buffer) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	source, err := format.Source(append(content, buf.Bytes()...))
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	return os.WriteFile(path, source, 0644)
+}
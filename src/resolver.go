@@ -0,0 +1,516 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2018 CNES
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package src
+
+import (
+	"strings"
+
+	"github.com/etiennelndr/archiveservice_generator/data"
+)
+
+// Kinds of the data types which can be defined in a specification
+const (
+	KindFundamental = "fundamental"
+	KindAttribute   = "attribute"
+	KindComposite   = "composite"
+	KindEnumeration = "enumeration"
+)
+
+// TypeDefinition is the definition of a data type in one of the areas
+// read by the generator
+type TypeDefinition struct {
+	Kind          string
+	Name          string
+	Area          string
+	Service       string
+	ShortFormPart string
+	// Short form of the concrete types
+	ShortForm ShortForm
+	Pos       data.Position
+}
+
+// String returns the qualified name of the definition
+func (d TypeDefinition) String() string {
+	return Type{Name: d.Name, Area: d.Area, Service: d.Service}.String()
+}
+
+func typeKey(area string, service string, name string) string {
+	return area + "::" + service + "::" + name
+}
+
+// indexTypes lists the data types defined by all the areas read and
+// returns the types defined twice
+func (g *Generator) indexTypes() []string {
+	g.types = make(map[string]TypeDefinition)
+	var problems []string
+	for _, area := range g.allAreas() {
+		base := baseShortForm(area, Service{})
+		var defs []TypeDefinition
+		for _, f := range area.Fundamentals {
+			defs = append(defs, TypeDefinition{Kind: KindFundamental, Name: f.Name, Pos: f.Pos})
+		}
+		for _, a := range area.Attributes {
+			def := TypeDefinition{Kind: KindAttribute, Name: a.Name, ShortFormPart: a.ShortFormPart, ShortForm: base, Pos: a.Pos}
+			def.ShortForm.ShortFormPart = a.shortFormPart
+			defs = append(defs, def)
+		}
+		defs = append(defs, dataDefinitions(area.Composites, area.Enumerations, base)...)
+		problems = append(problems, g.indexData(area.Name, "", defs)...)
+
+		for _, s := range area.Services {
+			defs := dataDefinitions(s.Composites, s.Enumerations, baseShortForm(area, s))
+			problems = append(problems, g.indexData(area.Name, s.Name, defs)...)
+		}
+	}
+	return problems
+}
+
+// dataDefinitions returns the definitions of the composites and the
+// enumerations of an area or a service, base is the short form of their
+// area and their service
+func dataDefinitions(composites []Composite, enumerations []Enumeration, base ShortForm) []TypeDefinition {
+	var defs []TypeDefinition
+	for _, c := range composites {
+		def := TypeDefinition{Kind: KindComposite, Name: c.Name, ShortFormPart: c.ShortFormPart, ShortForm: base, Pos: c.Pos}
+		def.ShortForm.ShortFormPart = c.shortFormPart
+		defs = append(defs, def)
+	}
+	for _, e := range enumerations {
+		def := TypeDefinition{Kind: KindEnumeration, Name: e.Name, ShortFormPart: e.ShortFormPart, ShortForm: base, Pos: e.Pos}
+		def.ShortForm.ShortFormPart = e.shortFormPart
+		defs = append(defs, def)
+	}
+	return defs
+}
+
+// indexData adds the definitions of the data types of an area or a
+// service and returns the types defined twice
+func (g *Generator) indexData(area string, service string, defs []TypeDefinition) []string {
+	var problems []string
+	for _, def := range defs {
+		def.Area = area
+		def.Service = service
+		key := typeKey(area, service, def.Name)
+		if other, ok := g.types[key]; ok {
+			problems = append(problems, problem(def.Pos, def.String(), "type is already defined at %s", other.Pos))
+			continue
+		}
+		g.types[key] = def
+	}
+	return problems
+}
+
+// LookupType finds the definition of a data type. If the service of
+// the type is not given, the type is searched in its area and then in
+// the service refService of the element referring to it.
+func (g *Generator) LookupType(area string, service string, name string, refService string) (TypeDefinition, bool) {
+	if service != "" {
+		def, ok := g.types[typeKey(area, service, name)]
+		return def, ok
+	}
+	if def, ok := g.types[typeKey(area, "", name)]; ok {
+		return def, true
+	}
+	if refService != "" {
+		def, ok := g.types[typeKey(area, refService, name)]
+		return def, ok
+	}
+	return TypeDefinition{}, false
+}
+
+// isAreaLoaded checks if an area has been read by the generator
+func (g *Generator) isAreaLoaded(name string) bool {
+	for _, area := range g.xmlRaw.AreaList {
+		if area.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveTypes finds the definition of every type referenced by the
+// model, it completes the references with the service, the kind and
+// the short form part of their definition. It returns the problems
+// found: types defined twice and dangling references.
+func (g *Generator) resolveTypes() []string {
+	problems := g.indexTypes()
+	for i := range g.Areas {
+		problems = append(problems, g.resolveArea(&g.Areas[i])...)
+	}
+	// The fields inherited are the resolved ones of the parents
+	for i := range g.Areas {
+		problems = append(problems, g.resolveInheritance(&g.Areas[i])...)
+	}
+	return problems
+}
+
+// resolveArea resolves the references made by an area and returns the
+// dangling ones
+func (g *Generator) resolveArea(area *Area) []string {
+	r := &resolver{g: g, area: area.Name}
+	for i := range area.Fundamentals {
+		f := &area.Fundamentals[i]
+		if f.NameOfTypeToExtend != "" {
+			def, ok := r.lookup(f.Pos, area.Name+"::"+f.Name, f.AreaOfTypeToExtend, "", f.NameOfTypeToExtend)
+			if ok {
+				f.AreaOfTypeToExtend = def.Area
+			}
+		}
+	}
+	for i := range area.Composites {
+		r.resolveComposite(area.Name, &area.Composites[i])
+	}
+	for i := range area.Errors {
+		r.resolveError(area.Name, &area.Errors[i])
+	}
+	for i := range area.Services {
+		s := &area.Services[i]
+		r.service = s.Name
+		path := area.Name + "::" + s.Name
+		for j := range s.Operations {
+			op := &s.Operations[j]
+			for k := range op.Pattern.Messages {
+				m := &op.Pattern.Messages[k]
+				for l := range m.Types {
+					r.resolveType(path+"::"+op.Name+"::"+m.Name, &m.Types[l])
+				}
+			}
+			for k := range op.Errors {
+				r.resolveOperationError(path+"::"+op.Name, &op.Errors[k])
+			}
+		}
+		for j := range s.Composites {
+			r.resolveComposite(path, &s.Composites[j])
+		}
+		for j := range s.Errors {
+			r.resolveError(path, &s.Errors[j])
+		}
+		for j := range s.Objects {
+			r.resolveObject(path, &s.Objects[j])
+		}
+		for j := range s.Events {
+			r.resolveObject(path, &s.Events[j])
+		}
+	}
+	return r.problems
+}
+
+// LookupComposite finds a composite of an area or, if service is
+// given, of one of its services
+func (g *Generator) LookupComposite(area string, service string, name string) (Composite, bool) {
+	for _, a := range g.allAreas() {
+		if a.Name != area {
+			continue
+		}
+		if service == "" {
+			for _, c := range a.Composites {
+				if c.Name == name {
+					return c, true
+				}
+			}
+			return Composite{}, false
+		}
+		for _, s := range a.Services {
+			if s.Name != service {
+				continue
+			}
+			for _, c := range s.Composites {
+				if c.Name == name {
+					return c, true
+				}
+			}
+		}
+	}
+	return Composite{}, false
+}
+
+// LookupObject finds a COM object or event of a service from its number
+func (g *Generator) LookupObject(area string, service string, number string) (COMObject, bool) {
+	for _, a := range g.allAreas() {
+		if a.Name != area {
+			continue
+		}
+		for _, s := range a.Services {
+			if s.Name != service {
+				continue
+			}
+			for _, o := range s.Objects {
+				if o.Number == number {
+					return o, true
+				}
+			}
+			for _, e := range s.Events {
+				if e.Number == number {
+					return e, true
+				}
+			}
+		}
+	}
+	return COMObject{}, false
+}
+
+// LookupError finds the definition of an error in an area or, if
+// service is given, in one of its services
+func (g *Generator) LookupError(area string, service string, name string) (Error, bool) {
+	for _, a := range g.allAreas() {
+		if a.Name != area {
+			continue
+		}
+		if service == "" {
+			for _, e := range a.Errors {
+				if e.Name == name {
+					return e, true
+				}
+			}
+			return Error{}, false
+		}
+		for _, s := range a.Services {
+			if s.Name != service {
+				continue
+			}
+			for _, e := range s.Errors {
+				if e.Name == name {
+					return e, true
+				}
+			}
+		}
+	}
+	return Error{}, false
+}
+
+// resolver resolves the references of an area and keeps the ones
+// which can't be resolved
+type resolver struct {
+	g        *Generator
+	area     string
+	service  string
+	problems []string
+}
+
+// lookup finds the definition of a reference made by the element at
+// pos and path, area is the one of the referring element if it is not
+// given
+func (r *resolver) lookup(pos data.Position, path string, area string, service string, name string) (TypeDefinition, bool) {
+	if area == "" {
+		area = r.area
+	}
+	def, ok := r.g.LookupType(area, service, name, r.service)
+	if ok {
+		return def, true
+	}
+
+	ref := Type{Name: name, Area: area, Service: service}.String()
+	if !r.g.isAreaLoaded(area) {
+		r.problems = append(r.problems, problem(pos, path, "unknown type %s (area %s is not loaded)", ref, area))
+	} else {
+		r.problems = append(r.problems, problem(pos, path, "unknown type %s", ref))
+	}
+	return def, false
+}
+
+func (r *resolver) resolveType(path string, t *Type) {
+	def, ok := r.lookup(t.Pos, path, t.Area, t.Service, t.Name)
+	if !ok {
+		return
+	}
+	t.Area = def.Area
+	t.Service = def.Service
+	t.Kind = def.Kind
+	t.ShortFormPart = def.ShortFormPart
+}
+
+func (r *resolver) resolveError(path string, e *Error) {
+	if e.HasExtraInformation() {
+		r.resolveType(path+"::"+e.Name, &e.ExtraInformation)
+	}
+}
+
+// resolveOperationError finds the definition of a referenced error,
+// its extra information is the one of the definition unless the
+// reference replaces it
+func (r *resolver) resolveOperationError(path string, e *OperationError) {
+	if !e.Reference {
+		e.Area = r.area
+		e.Service = r.service
+		r.resolveError(path, &e.Error)
+		return
+	}
+
+	if e.Area == "" {
+		e.Area = r.area
+	}
+	def, ok := r.g.LookupError(e.Area, e.Service, e.Name)
+	if !ok {
+		r.problems = append(r.problems, problem(e.Pos, path, "unknown error %s", e))
+		return
+	}
+	e.Number = def.Number
+	if e.Comment == "" {
+		e.Comment = def.Comment
+	}
+	if !e.HasExtraInformation() {
+		e.ExtraInformation = def.ExtraInformation
+		e.ExtraComment = def.ExtraComment
+		return
+	}
+	r.resolveType(path+"::"+e.Name, &e.ExtraInformation)
+}
+
+// resolveObject resolves the body type of a COM object and checks the
+// objects it refers to
+func (r *resolver) resolveObject(path string, o *COMObject) {
+	path += "::" + o.Name
+	if o.HasBody() {
+		r.resolveType(path, &o.ObjectType)
+	}
+	for _, ref := range []*ObjectReference{o.RelatedObject, o.SourceObject} {
+		if ref == nil || ref.Number == "" {
+			continue
+		}
+		if _, ok := r.g.LookupObject(ref.Area, ref.Service, ref.Number); !ok {
+			r.problems = append(r.problems, problem(o.Pos, path, "unknown object %s::%s::%s", ref.Area, ref.Service, ref.Number))
+		}
+	}
+}
+
+func (r *resolver) resolveComposite(path string, c *Composite) {
+	path += "::" + c.Name
+	if c.NameOfTypeToExtend != "" {
+		def, ok := r.lookup(c.Pos, path, c.AreaOfTypeToExtend, c.ServiceOfTypeToExtend, c.NameOfTypeToExtend)
+		if ok {
+			c.AreaOfTypeToExtend = def.Area
+			c.ServiceOfTypeToExtend = def.Service
+		}
+	}
+	for i := range c.Fields {
+		f := &c.Fields[i]
+		def, ok := r.lookup(f.Pos, path+"::"+f.Name, f.TypeArea, f.TypeService, f.TypeName)
+		if ok {
+			f.TypeArea = def.Area
+			f.TypeService = def.Service
+			f.TypeKind = def.Kind
+		}
+	}
+}
+
+// resolveInheritance resolves the chain of the types extended by each
+// composite of an area and the fields it inherits, it returns the
+// cycles and the parents which are not composites
+func (g *Generator) resolveInheritance(area *Area) []string {
+	var problems []string
+	for i := range area.Composites {
+		problems = append(problems, g.resolveExtends(area.Name, "", &area.Composites[i])...)
+	}
+	for i := range area.Services {
+		s := &area.Services[i]
+		for j := range s.Composites {
+			problems = append(problems, g.resolveExtends(area.Name, s.Name, &s.Composites[j])...)
+		}
+	}
+	return problems
+}
+
+// resolveExtends follows the parents of a composite up to a fundamental
+// type. The unknown parents are reported by resolveComposite.
+func (g *Generator) resolveExtends(area string, service string, c *Composite) []string {
+	self := Type{Name: c.Name, Area: area, Service: service}.String()
+	c.Extends = nil
+	c.inherited = nil
+
+	var parents []Composite
+	names := []string{c.Name}
+	seen := map[string]bool{self: true}
+	current := *c
+	for current.NameOfTypeToExtend != "" {
+		t := Type{Name: current.NameOfTypeToExtend, Area: current.AreaOfTypeToExtend, Service: current.ServiceOfTypeToExtend}
+		def, ok := g.LookupType(t.Area, t.Service, t.Name, "")
+		if !ok {
+			break
+		}
+		t.Kind = def.Kind
+		t.ShortFormPart = def.ShortFormPart
+		names = append(names, t.Name)
+		if def.Kind == KindFundamental {
+			c.Extends = append(c.Extends, t)
+			break
+		}
+		if def.Kind != KindComposite {
+			return []string{problem(c.Pos, self, "extends %s which is not a composite", t)}
+		}
+		if seen[t.String()] {
+			// The composites of the cycle report it, not the ones
+			// extending them
+			if t.String() == self {
+				return []string{problem(c.Pos, self, "cycle in the extends hierarchy: %s", strings.Join(names, " -> "))}
+			}
+			return nil
+		}
+		seen[t.String()] = true
+
+		parent, _ := g.LookupComposite(t.Area, t.Service, t.Name)
+		c.Extends = append(c.Extends, t)
+		parents = append(parents, parent)
+		current = parent
+	}
+
+	for i := len(parents) - 1; i >= 0; i-- {
+		c.inherited = append(c.inherited, parents[i].Fields...)
+	}
+	return nil
+}
+
+// ConcreteSubtypes returns the concrete composites of all the areas
+// read which extend t directly or not, e.g. CompositeFilterSet for
+// COM::Archive::QueryFilter. The model must have been validated.
+func (g *Generator) ConcreteSubtypes(t Type) []Type {
+	var subtypes []Type
+	add := func(area string, service string, composites []Composite) {
+		for _, c := range composites {
+			if c.IsAbstract() {
+				continue
+			}
+			for _, parent := range c.Extends {
+				if parent.Name == t.Name && parent.Area == t.Area && parent.Service == t.Service {
+					subtypes = append(subtypes, Type{
+						Name:          c.Name,
+						Area:          area,
+						Service:       service,
+						ShortFormPart: c.ShortFormPart,
+						Kind:          KindComposite,
+						Pos:           c.Pos,
+					})
+					break
+				}
+			}
+		}
+	}
+	for _, a := range g.allAreas() {
+		add(a.Name, "", a.Composites)
+		for _, s := range a.Services {
+			add(a.Name, s.Name, s.Composites)
+		}
+	}
+	return subtypes
+}
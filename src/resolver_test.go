@@ -0,0 +1,208 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2018 CNES
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package src
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// malSpecification is the specification of the MAL bundled with the
+// generator
+const malSpecification = "../XML/ServiceDefMAL.xml"
+
+// testArea is an area using the MAL, the operations and the data types
+// of its Test service are given by the tests
+const testArea = `<?xml version="1.0" encoding="UTF-8"?>
+<mal:specification xmlns:mal="http://www.ccsds.org/schema/ServiceSchema">
+  <mal:area name="TEST" number="100" version="1">
+    <mal:service name="Test" number="1">
+      <mal:capabilitySet number="1">
+        <mal:sendIP name="ping" number="1" supportInReplay="false">
+          <mal:messages>
+            <mal:send/>
+          </mal:messages>
+        </mal:sendIP>
+%s
+      </mal:capabilitySet>
+      <mal:dataTypes>
+        <mal:enumeration name="Kind" shortFormPart="1">
+          <mal:item value="FIRST" nvalue="1"/>
+        </mal:enumeration>
+%s
+      </mal:dataTypes>
+    </mal:service>
+  </mal:area>
+</mal:specification>
+`
+
+// loadTestArea reads the MAL and the test area with the operations and
+// the data types given, and returns the generator and the result of
+// its validation
+func loadTestArea(t *testing.T, operations string, dataTypes string) (*Generator, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ServiceDefTEST.xml")
+	content := strings.Replace(testArea, "%s", operations, 1)
+	content = strings.Replace(content, "%s", dataTypes, 1)
+	err := os.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := new(Generator)
+	for _, file := range []string{malSpecification, path} {
+		err = g.OpenAndReadXML(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	g.RetrieveInformation()
+	return g, g.Validate()
+}
+
+// composite returns a composite of the test service extending the type
+// parent, it is abstract if shortFormPart is empty
+func composite(name string, shortFormPart string, parent string) string {
+	if shortFormPart != "" {
+		shortFormPart = ` shortFormPart="` + shortFormPart + `"`
+	}
+	area := "TEST"
+	if parent == "Composite" {
+		area = "MAL"
+	}
+	return `<mal:composite name="` + name + `"` + shortFormPart + `>
+          <mal:extends>
+            <mal:type name="` + parent + `" area="` + area + `"/>
+          </mal:extends>
+        </mal:composite>
+`
+}
+
+func TestResolveExtends(t *testing.T) {
+	tests := []struct {
+		name      string
+		dataTypes string
+		problems  []string
+	}{
+		{
+			name:      "valid",
+			dataTypes: composite("Base", "", "Composite") + composite("Child", "2", "Base"),
+		},
+		{
+			name:      "cycle",
+			dataTypes: composite("LoopA", "2", "LoopB") + composite("LoopB", "3", "LoopA"),
+			problems: []string{
+				"TEST::Test::LoopA: cycle in the extends hierarchy: LoopA -> LoopB -> LoopA",
+				"TEST::Test::LoopB: cycle in the extends hierarchy: LoopB -> LoopA -> LoopB",
+			},
+		},
+		{
+			name:      "self",
+			dataTypes: composite("Loop", "2", "Loop"),
+			problems:  []string{"TEST::Test::Loop: cycle in the extends hierarchy: Loop -> Loop"},
+		},
+		{
+			name:      "not a composite",
+			dataTypes: composite("OnEnum", "2", "Kind"),
+			problems:  []string{"TEST::Test::OnEnum: extends TEST::Test::Kind which is not a composite"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := loadTestArea(t, "", test.dataTypes)
+			checkProblems(t, err, test.problems)
+		})
+	}
+}
+
+func TestAllFields(t *testing.T) {
+	dataTypes := `<mal:composite name="Base">
+          <mal:extends>
+            <mal:type name="Composite" area="MAL"/>
+          </mal:extends>
+          <mal:field name="id">
+            <mal:type name="Long" area="MAL"/>
+          </mal:field>
+        </mal:composite>
+        <mal:composite name="Child" shortFormPart="2">
+          <mal:extends>
+            <mal:type name="Base" area="TEST"/>
+          </mal:extends>
+          <mal:field name="name">
+            <mal:type name="String" area="MAL"/>
+          </mal:field>
+        </mal:composite>`
+	g, err := loadTestArea(t, "", dataTypes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, ok := g.LookupComposite("TEST", "Test", "Child")
+	if !ok {
+		t.Fatal("TEST::Test::Child not found")
+	}
+	var names []string
+	for _, f := range c.AllFields() {
+		names = append(names, f.Name)
+	}
+	if strings.Join(names, " ") != "id name" {
+		t.Errorf("fields of Child are %v, want [id name]", names)
+	}
+}
+
+// checkProblems checks that the error of Validate reports exactly the
+// problems given, in any order
+func checkProblems(t *testing.T, err error, problems []string) {
+	t.Helper()
+	if len(problems) == 0 {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return
+	}
+	if err == nil {
+		t.Fatalf("no error, want %v", problems)
+	}
+
+	reported := strings.Split(err.Error(), "\n\t")[1:]
+	if len(reported) != len(problems) {
+		t.Errorf("%d problems reported, want %d:\n%v", len(reported), len(problems), err)
+	}
+	for _, p := range problems {
+		found := false
+		for _, r := range reported {
+			if strings.HasSuffix(r, p) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("problem %q not reported:\n%v", p, err)
+		}
+	}
+}
@@ -0,0 +1,725 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2018 CNES
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package src
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/etiennelndr/archiveservice_generator/data"
+)
+
+// Namespaces of the XML schemas
+const (
+	xsdNamespace = "http://www.w3.org/2001/XMLSchema"
+	xsiNamespace = "http://www.w3.org/2001/XMLSchema-instance"
+)
+
+// Files of the schemas of the specifications
+const (
+	ServiceSchemaFile = "ServiceSchema.xsd"
+	COMSchemaFile     = "COMSchema.xsd"
+)
+
+// xmlNode is an element of an XML document with its position
+type xmlNode struct {
+	name     xml.Name
+	attrs    []xml.Attr
+	children []*xmlNode
+	// Prefixes of the namespaces in scope
+	namespaces map[string]string
+	pos        data.Position
+	// Schema declaring the node, for the nodes of a schema
+	schema *schemaDocument
+}
+
+// attr returns the value of an unqualified attribute
+func (n *xmlNode) attr(name string) (string, bool) {
+	for _, a := range n.attrs {
+		if a.Name.Space == "" && a.Name.Local == name {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// qualifiedName resolves a name with a prefix, e.g. mal:AreaType, with
+// the namespaces in scope of the node
+func (n *xmlNode) qualifiedName(value string) xml.Name {
+	prefix, local := "", value
+	if i := strings.Index(value, ":"); i >= 0 {
+		prefix, local = value[:i], value[i+1:]
+	}
+	return xml.Name{Space: n.namespaces[prefix], Local: local}
+}
+
+// xsdChildren returns the children of a node of a schema with one of
+// the names, all the children if none is given
+func (n *xmlNode) xsdChildren(names ...string) []*xmlNode {
+	var children []*xmlNode
+	for _, c := range n.children {
+		if c.name.Space != xsdNamespace {
+			continue
+		}
+		if len(names) == 0 {
+			children = append(children, c)
+			continue
+		}
+		for _, name := range names {
+			if c.name.Local == name {
+				children = append(children, c)
+			}
+		}
+	}
+	return children
+}
+
+// readXMLNodes reads the tree of the elements of an XML document, the
+// errors start with their position in file
+func readXMLNodes(file string, content []byte) (*xmlNode, error) {
+	lines := data.NewLines(file, content)
+	var root *xmlNode
+	var stack []*xmlNode
+	d := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		offset := d.InputOffset()
+		tok, err := d.Token()
+		if err == io.EOF {
+			if root == nil {
+				return nil, fmt.Errorf("%s: no root element", lines.Position(int64(len(content))))
+			}
+			return root, nil
+		}
+		if err != nil {
+			// The decoder stops where the document is invalid
+			pos := lines.Position(d.InputOffset())
+			if syntax, ok := err.(*xml.SyntaxError); ok {
+				return nil, fmt.Errorf("%s: %s", pos, syntax.Msg)
+			}
+			return nil, fmt.Errorf("%s: %s", pos, err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &xmlNode{name: t.Name, pos: lines.Position(offset), namespaces: map[string]string{}}
+			if len(stack) != 0 {
+				parent := stack[len(stack)-1]
+				for prefix, space := range parent.namespaces {
+					n.namespaces[prefix] = space
+				}
+				parent.children = append(parent.children, n)
+			} else {
+				root = n
+			}
+			for _, a := range t.Attr {
+				switch {
+				case a.Name.Space == "xmlns":
+					n.namespaces[a.Name.Local] = a.Value
+				case a.Name.Space == "" && a.Name.Local == "xmlns":
+					n.namespaces[""] = a.Value
+				default:
+					n.attrs = append(n.attrs, a)
+				}
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
+
+// schemaDocument is one of the files of a schema
+type schemaDocument struct {
+	target    string
+	qualified bool
+}
+
+// Schema is an XML schema checking the specifications. Only the parts of
+// XML Schema used by the schemas of the MAL and the COM are supported.
+type Schema struct {
+	elements map[xml.Name]*xmlNode
+	types    map[xml.Name]*xmlNode
+	loaded   map[string]bool
+}
+
+// LoadSchema reads the files of a schema and the ones they import
+func LoadSchema(paths ...string) (*Schema, error) {
+	s := &Schema{
+		elements: map[xml.Name]*xmlNode{},
+		types:    map[xml.Name]*xmlNode{},
+		loaded:   map[string]bool{},
+	}
+	for _, path := range paths {
+		err := s.load(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *Schema) load(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if s.loaded[abs] {
+		return nil
+	}
+	s.loaded[abs] = true
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	root, err := readXMLNodes(path, content)
+	if err != nil {
+		return err
+	}
+	if root.name.Space != xsdNamespace || root.name.Local != "schema" {
+		return fmt.Errorf("%s: not an XML schema", path)
+	}
+
+	doc := &schemaDocument{}
+	doc.target, _ = root.attr("targetNamespace")
+	form, _ := root.attr("elementFormDefault")
+	doc.qualified = form == "qualified"
+	setSchema(root, doc)
+
+	for _, c := range root.xsdChildren() {
+		name, _ := c.attr("name")
+		switch c.name.Local {
+		case "import", "include":
+			location, ok := c.attr("schemaLocation")
+			if !ok {
+				continue
+			}
+			err = s.load(filepath.Join(filepath.Dir(path), location))
+			if err != nil {
+				return err
+			}
+		case "element":
+			s.elements[xml.Name{Space: doc.target, Local: name}] = c
+		case "complexType", "simpleType":
+			s.types[xml.Name{Space: doc.target, Local: name}] = c
+		}
+	}
+	return nil
+}
+
+func setSchema(n *xmlNode, doc *schemaDocument) {
+	n.schema = doc
+	for _, c := range n.children {
+		setSchema(c, doc)
+	}
+}
+
+// ValidateFile checks an XML document with the schema and returns the
+// problems found, each prefixed with its position in the file
+func (s *Schema) ValidateFile(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	root, err := readXMLNodes(path, content)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &schemaValidator{schema: s}
+	if decl, ok := s.elements[root.name]; ok {
+		v.element(root, decl)
+	} else {
+		v.report(root, "unexpected root element %s", root.name.Local)
+	}
+
+	// The problems are reported in the order of the document
+	sort.SliceStable(v.problems, func(i, j int) bool {
+		return before(v.problems[i].pos, v.problems[j].pos)
+	})
+	var problems []string
+	for _, p := range v.problems {
+		problems = append(problems, fmt.Sprintf("%s: %s", p.pos, p.message))
+	}
+	return problems, nil
+}
+
+// schemaValidator checks a document and collects its problems
+type schemaValidator struct {
+	schema   *Schema
+	problems []schemaProblem
+}
+
+// schemaProblem is a problem found at a position of the document
+type schemaProblem struct {
+	pos     data.Position
+	message string
+}
+
+func (v *schemaValidator) report(n *xmlNode, format string, args ...interface{}) {
+	v.problems = append(v.problems, schemaProblem{n.pos, fmt.Sprintf(format, args...)})
+}
+
+// before reports whether the position a comes before b in their file
+func before(a data.Position, b data.Position) bool {
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+	return a.Column < b.Column
+}
+
+// element checks an element of the document with its declaration
+func (v *schemaValidator) element(n *xmlNode, decl *xmlNode) {
+	var complexType *xmlNode
+	if xsiType, ok := attrValue(n, xml.Name{Space: xsiNamespace, Local: "type"}); ok {
+		name := n.qualifiedName(xsiType)
+		complexType, ok = v.schema.types[name]
+		if !ok {
+			v.report(n, "unknown type %s of %s", xsiType, n.name.Local)
+			return
+		}
+	} else if typeName, ok := decl.attr("type"); ok {
+		name := decl.qualifiedName(typeName)
+		complexType, ok = v.schema.types[name]
+		if !ok {
+			// The built-in types have no content to check
+			return
+		}
+	} else if inline := decl.xsdChildren("complexType"); len(inline) != 0 {
+		complexType = inline[0]
+	} else {
+		return
+	}
+	if abstract, _ := complexType.attr("abstract"); abstract == "true" {
+		v.report(n, "%s has an abstract type", n.name.Local)
+	}
+
+	particles, attributes := v.content(complexType)
+	v.attributes(n, attributes)
+	v.children(n, particles)
+	for _, constraint := range decl.xsdChildren("unique", "key") {
+		v.unique(n, constraint)
+	}
+}
+
+// attrValue returns the value of a qualified attribute
+func attrValue(n *xmlNode, name xml.Name) (string, bool) {
+	for _, a := range n.attrs {
+		if a.Name == name {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// content returns the particles and the attributes of a complex type,
+// including the ones of the type it extends
+func (v *schemaValidator) content(complexType *xmlNode) ([]*xmlNode, []*xmlNode) {
+	particles := complexType.xsdChildren("sequence", "choice", "all")
+	attributes := complexType.xsdChildren("attribute")
+	for _, cc := range complexType.xsdChildren("complexContent") {
+		for _, ext := range cc.xsdChildren("extension") {
+			if baseName, ok := ext.attr("base"); ok {
+				if base, ok := v.schema.types[ext.qualifiedName(baseName)]; ok {
+					baseParticles, baseAttributes := v.content(base)
+					particles = append(particles, baseParticles...)
+					attributes = append(attributes, baseAttributes...)
+				}
+			}
+			particles = append(particles, ext.xsdChildren("sequence", "choice", "all")...)
+			attributes = append(attributes, ext.xsdChildren("attribute")...)
+		}
+	}
+	return particles, attributes
+}
+
+// attributes checks the attributes of an element
+func (v *schemaValidator) attributes(n *xmlNode, attributes []*xmlNode) {
+	declared := map[string]*xmlNode{}
+	for _, a := range attributes {
+		name, _ := a.attr("name")
+		declared[name] = a
+		value, ok := n.attr(name)
+		if !ok {
+			if use, _ := a.attr("use"); use == "required" {
+				v.report(n, "%s has no %s attribute", n.name.Local, name)
+			}
+			continue
+		}
+		if typeName, ok := a.attr("type"); ok {
+			if problem := v.simpleValue(a.qualifiedName(typeName), value); problem != "" {
+				v.report(n, "attribute %s of %s: %s", name, n.name.Local, problem)
+			}
+		}
+	}
+
+	for _, a := range n.attrs {
+		if a.Name.Space == xsiNamespace || a.Name.Space == "http://www.w3.org/XML/1998/namespace" {
+			continue
+		}
+		if _, ok := declared[a.Name.Local]; !ok || a.Name.Space != "" {
+			v.report(n, "unexpected attribute %s of %s", a.Name.Local, n.name.Local)
+		}
+	}
+}
+
+// simpleValue checks the value of a simple type, it returns the problem
+// found or an empty string
+func (v *schemaValidator) simpleValue(name xml.Name, value string) string {
+	if name.Space == xsdNamespace {
+		return builtinValue(name.Local, value)
+	}
+	simpleType, ok := v.schema.types[name]
+	if !ok {
+		return ""
+	}
+	for _, r := range simpleType.xsdChildren("restriction") {
+		base, _ := r.attr("base")
+		if problem := v.simpleValue(r.qualifiedName(base), value); problem != "" {
+			return problem
+		}
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			continue
+		}
+		for _, facet := range r.xsdChildren("minInclusive", "maxInclusive") {
+			limit, _ := facet.attr("value")
+			l, err := strconv.ParseInt(limit, 10, 64)
+			if err != nil {
+				continue
+			}
+			if facet.name.Local == "minInclusive" && n < l || facet.name.Local == "maxInclusive" && n > l {
+				return fmt.Sprintf("%s is not in the range of %s", value, name.Local)
+			}
+		}
+	}
+	return ""
+}
+
+// builtinValue checks the value of a built-in type of XML Schema
+func builtinValue(name string, value string) string {
+	value = strings.TrimSpace(value)
+	bits := 0
+	switch name {
+	case "boolean":
+		switch value {
+		case "true", "false", "1", "0":
+			return ""
+		}
+		return fmt.Sprintf("%q is not a boolean", value)
+	case "NCName":
+		if !isNCName(value) {
+			return fmt.Sprintf("%q is not a valid name", value)
+		}
+		return ""
+	case "unsignedByte":
+		bits = 8
+	case "unsignedShort":
+		bits = 16
+	case "unsignedInt":
+		bits = 32
+	case "unsignedLong":
+		bits = 64
+	default:
+		return ""
+	}
+	if _, err := strconv.ParseUint(value, 10, bits); err != nil {
+		return fmt.Sprintf("%q is not an %s", value, name)
+	}
+	return ""
+}
+
+func isNCName(value string) bool {
+	if value == "" {
+		return false
+	}
+	for i, c := range value {
+		letter := c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c > 0x7F
+		if i == 0 && !letter {
+			return false
+		}
+		if !letter && !(c >= '0' && c <= '9') && c != '-' && c != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// children checks the order and the number of the children of an
+// element, then each of them with its declaration
+func (v *schemaValidator) children(n *xmlNode, particles []*xmlNode) {
+	m := &contentMatcher{v: v, children: n.children}
+	ends := m.sequence(particles, map[int]bool{0: true})
+	if !ends[len(n.children)] {
+		if m.farthest < len(n.children) {
+			c := n.children[m.farthest]
+			v.report(c, "unexpected element %s in %s", c.name.Local, n.name.Local)
+		} else {
+			v.report(n, "%s is incomplete, an element is missing at its end", n.name.Local)
+		}
+	}
+
+	// The children are checked even if they are misplaced, the ones
+	// which are not declared were reported above
+	for _, c := range n.children {
+		if decl := v.declaration(particles, c.name); decl != nil {
+			v.element(c, decl)
+		} else if decl, ok := v.schema.elements[c.name]; ok {
+			// The wildcards check the elements they know
+			v.element(c, decl)
+		}
+	}
+}
+
+// declaration finds the declaration of an element in particles
+func (v *schemaValidator) declaration(particles []*xmlNode, name xml.Name) *xmlNode {
+	for _, p := range particles {
+		if p.name.Local == "element" {
+			if decl := v.elementDeclaration(p); decl != nil && v.elementName(p) == name {
+				return decl
+			}
+			continue
+		}
+		if decl := v.declaration(p.xsdChildren("sequence", "choice", "all", "element"), name); decl != nil {
+			return decl
+		}
+	}
+	return nil
+}
+
+// elementDeclaration returns the declaration of an element particle,
+// the global element it refers to if it is a reference
+func (v *schemaValidator) elementDeclaration(p *xmlNode) *xmlNode {
+	if ref, ok := p.attr("ref"); ok {
+		return v.schema.elements[p.qualifiedName(ref)]
+	}
+	return p
+}
+
+// elementName returns the name of the elements matching an element
+// particle
+func (v *schemaValidator) elementName(p *xmlNode) xml.Name {
+	if ref, ok := p.attr("ref"); ok {
+		return p.qualifiedName(ref)
+	}
+	name, _ := p.attr("name")
+	if p.schema.qualified {
+		return xml.Name{Space: p.schema.target, Local: name}
+	}
+	return xml.Name{Local: name}
+}
+
+// contentMatcher matches the children of an element with the particles
+// of its type. Each step computes the set of the positions in the
+// children which can be reached.
+type contentMatcher struct {
+	v        *schemaValidator
+	children []*xmlNode
+	farthest int
+}
+
+func (m *contentMatcher) sequence(particles []*xmlNode, from map[int]bool) map[int]bool {
+	for _, p := range particles {
+		from = m.occurrences(p, from)
+	}
+	return from
+}
+
+// occurrences matches a particle between minOccurs and maxOccurs times
+func (m *contentMatcher) occurrences(p *xmlNode, from map[int]bool) map[int]bool {
+	min, max := 1, 1
+	if value, ok := p.attr("minOccurs"); ok {
+		min, _ = strconv.Atoi(value)
+	}
+	if value, ok := p.attr("maxOccurs"); ok {
+		if value == "unbounded" {
+			max = -1
+		} else {
+			max, _ = strconv.Atoi(value)
+		}
+	}
+
+	ends := map[int]bool{}
+	if min == 0 {
+		union(ends, from)
+	}
+	seen := map[int]bool{}
+	current := from
+	for i := 1; max < 0 || i <= max; i++ {
+		current = m.once(p, current)
+		if len(current) == 0 {
+			break
+		}
+		if i >= min {
+			union(ends, current)
+		}
+		if !union(seen, current) && i >= min {
+			break
+		}
+	}
+	return ends
+}
+
+// once matches a particle once
+func (m *contentMatcher) once(p *xmlNode, from map[int]bool) map[int]bool {
+	switch p.name.Local {
+	case "sequence":
+		return m.sequence(p.xsdChildren("element", "sequence", "choice", "any"), from)
+	case "choice", "all":
+		ends := map[int]bool{}
+		for _, c := range p.xsdChildren("element", "sequence", "choice", "any") {
+			union(ends, m.occurrences(c, from))
+		}
+		return ends
+	}
+
+	ends := map[int]bool{}
+	for pos := range from {
+		if pos >= len(m.children) {
+			continue
+		}
+		if p.name.Local == "any" || m.v.elementName(p) == m.children[pos].name {
+			ends[pos+1] = true
+			if pos+1 > m.farthest {
+				m.farthest = pos + 1
+			}
+		}
+	}
+	return ends
+}
+
+// union adds the positions of from to to and reports if one was added
+func union(to map[int]bool, from map[int]bool) bool {
+	added := false
+	for pos := range from {
+		if !to[pos] {
+			to[pos] = true
+			added = true
+		}
+	}
+	return added
+}
+
+// unique checks an identity constraint of an element, the values of the
+// field of the selected elements must be unique
+func (v *schemaValidator) unique(n *xmlNode, constraint *xmlNode) {
+	name, _ := constraint.attr("name")
+	var selector, field string
+	for _, s := range constraint.xsdChildren("selector") {
+		selector, _ = s.attr("xpath")
+	}
+	for _, f := range constraint.xsdChildren("field") {
+		field, _ = f.attr("xpath")
+	}
+	if !strings.HasPrefix(field, "@") {
+		return
+	}
+
+	first := map[string]*xmlNode{}
+	for _, selected := range selectNodes(n, selector, constraint) {
+		value, ok := selected.attr(strings.TrimPrefix(field, "@"))
+		if !ok {
+			continue
+		}
+		if other, ok := first[value]; ok {
+			v.report(selected, "%s %q of %s is already used at line %d (%s)", field[1:], value, selected.name.Local, other.pos.Line, name)
+			continue
+		}
+		first[value] = selected
+	}
+}
+
+// selectNodes returns the elements selected by the XPath of a selector,
+// only the unions of relative paths of names or wildcards, which may
+// start with .//, are supported
+func selectNodes(n *xmlNode, xpath string, scope *xmlNode) []*xmlNode {
+	var selected []*xmlNode
+	seen := map[*xmlNode]bool{}
+	for _, path := range strings.Split(xpath, "|") {
+		path = strings.TrimSpace(path)
+		nodes := []*xmlNode{n}
+		descendant := strings.HasPrefix(path, ".//")
+		if descendant {
+			path = strings.TrimPrefix(path, ".//")
+			nodes = descendants(n)
+		}
+		for i, step := range strings.Split(path, "/") {
+			var next []*xmlNode
+			for _, node := range nodes {
+				if i == 0 && descendant {
+					// The first step of a descendant path applies to the
+					// nodes themselves
+					if matchStep(node, step, scope) {
+						next = append(next, node)
+					}
+					continue
+				}
+				for _, c := range node.children {
+					if matchStep(c, step, scope) {
+						next = append(next, c)
+					}
+				}
+			}
+			nodes = next
+		}
+		for _, node := range nodes {
+			if !seen[node] {
+				seen[node] = true
+				selected = append(selected, node)
+			}
+		}
+	}
+	// The first of the duplicates in the document is kept
+	sort.SliceStable(selected, func(i, j int) bool {
+		return before(selected[i].pos, selected[j].pos)
+	})
+	return selected
+}
+
+// descendants returns the descendants of a node
+func descendants(n *xmlNode) []*xmlNode {
+	var nodes []*xmlNode
+	for _, c := range n.children {
+		nodes = append(nodes, c)
+		nodes = append(nodes, descendants(c)...)
+	}
+	return nodes
+}
+
+// matchStep checks if a node matches a step of an XPath, its prefix is
+// resolved with the namespaces of the schema
+func matchStep(n *xmlNode, step string, scope *xmlNode) bool {
+	if step == "*" {
+		return true
+	}
+	return n.name == scope.qualifiedName(step)
+}
@@ -0,0 +1,116 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2018 CNES
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package src
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// comSpecification is the specification of the COM bundled with the
+// generator
+const comSpecification = "../XML/ServiceDefCOM.xml"
+
+func TestSchemaValidateFile(t *testing.T) {
+	schema, err := LoadSchema(filepath.Join("../XML", ServiceSchemaFile), filepath.Join("../XML", COMSchemaFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(comSpecification)
+	if err != nil {
+		t.Fatal(err)
+	}
+	com := string(content)
+	// The COM specification truncated after its 20th line
+	truncated := strings.Join(strings.SplitAfter(com, "\n")[:20], "")
+
+	tests := []struct {
+		name     string
+		content  string
+		problems []string
+		err      string
+	}{
+		{
+			name:    "valid",
+			content: com,
+		},
+		{
+			name:     "misspelled element",
+			content:  strings.Replace(com, "mal:pubsubIP", "mal:pubSubIP", -1),
+			problems: []string{":11:9: unexpected element pubSubIP in capabilitySet"},
+		},
+		{
+			name:     "missing attribute",
+			content:  strings.Replace(com, `<mal:pubsubIP name="monitorEvent" number="1" supportInReplay="true"`, `<mal:pubsubIP name="monitorEvent" number="1"`, 1),
+			problems: []string{":11:9: pubsubIP has no supportInReplay attribute"},
+		},
+		{
+			name:    "truncated",
+			content: truncated,
+			err:     ":21:1: unexpected EOF",
+		},
+		{
+			name:    "mismatched end tag",
+			content: strings.Replace(com, "</mal:pubsubIP>", "</mal:pubsubOp>", 1),
+			err:     ":19:24: element <pubsubIP> closed by </pubsubOp>",
+		},
+		{
+			name:    "empty",
+			content: "",
+			err:     ":1:1: no root element",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "ServiceDefCOM.xml")
+			err := os.WriteFile(path, []byte(test.content), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			problems, err := schema.ValidateFile(path)
+			if test.err != "" {
+				if err == nil || err.Error() != path+test.err {
+					t.Fatalf("error = %v, want %s", err, path+test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(problems) != len(test.problems) {
+				t.Fatalf("problems = %q, want %q", problems, test.problems)
+			}
+			for i, p := range test.problems {
+				if problems[i] != path+p {
+					t.Errorf("problem = %s, want %s", problems[i], path+p)
+				}
+			}
+		})
+	}
+}
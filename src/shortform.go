@@ -0,0 +1,136 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2018 CNES
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package src
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"github.com/etiennelndr/archiveservice_generator/data"
+)
+
+// Ranges of the numbers of a specification
+const (
+	maxUShort        = 1<<16 - 1
+	maxUOctet        = 1<<8 - 1
+	maxUInteger      = 1<<32 - 1
+	maxShortFormPart = 1<<23 - 1
+)
+
+// parseNumber parses a number of the specification, it must be between
+// min and max
+func parseNumber(value string, min int64, max int64) (int64, error) {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", value)
+	}
+	if n < min || n > max {
+		return 0, fmt.Errorf("%d is not between %d and %d", n, min, max)
+	}
+	return n, nil
+}
+
+// ShortForm identifies a MAL type in the encoded polymorphic values,
+// with the numbers of its area and its service, the version of its area
+// and its short form part. The short form part of a list is the negated
+// one of its type.
+type ShortForm struct {
+	Area          uint16
+	Service       uint16
+	Version       uint8
+	ShortFormPart int32
+}
+
+// number parses a number of the specification while the model is
+// built, what names it in the problem reported by Validate if it is not
+// between min and max. An invalid number is read as 0.
+func (g *Generator) number(pos data.Position, path string, what string, value string, min int64, max int64) int64 {
+	n, err := parseNumber(value, min, max)
+	if err != nil {
+		g.problems = append(g.problems, problem(pos, path, "%s %v", what, err))
+	}
+	return n
+}
+
+// shortFormPart parses the short form part of a type, the abstract
+// types have none and get 0
+func (g *Generator) shortFormPart(pos data.Position, path string, value string) int32 {
+	if value == "" {
+		return 0
+	}
+	return int32(g.number(pos, path, "short form part", value, 1, maxShortFormPart))
+}
+
+// baseShortForm returns the short form of the types of an area or, if
+// service is not the zero value, of one of its services, without short
+// form part
+func baseShortForm(area Area, service Service) ShortForm {
+	return ShortForm{Area: area.number, Service: service.number, Version: area.version}
+}
+
+// List returns the short form of the list of the type
+func (sf ShortForm) List() ShortForm {
+	sf.ShortFormPart = -sf.ShortFormPart
+	return sf
+}
+
+// Absolute returns the 64-bit absolute short form, 0xAAAASSSSVVPPPPPP
+// in hex where the short form part is on 24 bits
+func (sf ShortForm) Absolute() int64 {
+	return int64(uint64(sf.Area)<<48 | uint64(sf.Service)<<32 | uint64(sf.Version)<<24 | uint64(sf.ShortFormPart)&0xFFFFFF)
+}
+
+// String returns the absolute short form as an hexadecimal constant
+func (sf ShortForm) String() string {
+	if n := sf.Absolute(); n < 0 {
+		return fmt.Sprintf("-0x%x", uint64(-n))
+	}
+	return fmt.Sprintf("0x%x", sf.Absolute())
+}
+
+// ShortForm returns the short form of a resolved type or of its list,
+// ok is false if the type has none because it is abstract
+func (g *Generator) ShortForm(t Type) (sf ShortForm, ok bool) {
+	def, ok := g.LookupType(t.Area, t.Service, t.Name, "")
+	if !ok || def.ShortFormPart == "" {
+		return ShortForm{}, false
+	}
+	if t.IsList() {
+		return def.ShortForm.List(), true
+	}
+	return def.ShortForm, true
+}
+
+// writeShortForms writes the constants of the short form part and the
+// absolute short form of a type, constant is the prefix of their names
+func (g *Generator) writeShortForms(buf *bytes.Buffer, name string, constant string, t Type) {
+	sf, _ := g.ShortForm(t)
+	buf.WriteString("\n// Short forms of the " + name + " type\n")
+	buf.WriteString("const (\n")
+	buf.WriteString("\t" + constant + "_TYPE_SHORT_FORM mal.Integer = " + strconv.Itoa(int(sf.ShortFormPart)) + "\n")
+	buf.WriteString("\t" + constant + "_SHORT_FORM      mal.Long    = " + sf.String() + "\n")
+	buf.WriteString(")\n")
+}
@@ -0,0 +1,145 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2018 CNES
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package src
+
+import "testing"
+
+func TestShortForm(t *testing.T) {
+	g, err := loadTestArea(t, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		t         Type
+		ok        bool
+		part      int32
+		shortForm string
+	}{
+		{Type{Area: "MAL", Name: "Blob"}, true, 1, "0x1000001000001"},
+		{Type{Area: "MAL", Name: "Blob", List: "true"}, true, -1, "0x1000001ffffff"},
+		{Type{Area: "MAL", Name: "URI"}, true, 18, "0x1000001000012"},
+		{Type{Area: "MAL", Name: "IdBooleanPair"}, true, 27, "0x100000100001b"},
+		{Type{Area: "MAL", Name: "IdBooleanPair", List: "true"}, true, -27, "0x1000001ffffe5"},
+		{Type{Area: "TEST", Service: "Test", Name: "Kind"}, true, 1, "0x64000101000001"},
+		{Type{Area: "MAL", Name: "Element"}, false, 0, ""},
+		{Type{Area: "MAL", Name: "Unknown"}, false, 0, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.t.String(), func(t *testing.T) {
+			sf, ok := g.ShortForm(test.t)
+			if ok != test.ok {
+				t.Fatalf("ShortForm() ok = %v, want %v", ok, test.ok)
+			}
+			if !ok {
+				return
+			}
+			if sf.ShortFormPart != test.part {
+				t.Errorf("short form part = %d, want %d", sf.ShortFormPart, test.part)
+			}
+			if sf.String() != test.shortForm {
+				t.Errorf("short form = %s, want %s", sf, test.shortForm)
+			}
+		})
+	}
+}
+
+func TestShortFormString(t *testing.T) {
+	tests := []struct {
+		sf   ShortForm
+		want string
+	}{
+		{ShortForm{Area: 2, Service: 2, Version: 1, ShortFormPart: 1}, "0x2000201000001"},
+		{ShortForm{Area: 2, Service: 2, Version: 1, ShortFormPart: 1}.List(), "0x2000201ffffff"},
+		{ShortForm{Area: 2, Version: 1, ShortFormPart: maxShortFormPart}, "0x20000017fffff"},
+		{ShortForm{Area: 0x8000, Version: 1, ShortFormPart: 1}, "-0x7ffffffffeffffff"},
+	}
+
+	for _, test := range tests {
+		if got := test.sf.String(); got != test.want {
+			t.Errorf("%+v: String() = %s, want %s", test.sf, got, test.want)
+		}
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		value string
+		max   int64
+		want  int64
+		err   string
+	}{
+		{"1", maxUShort, 1, ""},
+		{"65535", maxUShort, 65535, ""},
+		{"65536", maxUShort, 0, "65536 is not between 1 and 65535"},
+		{"0", maxUOctet, 0, "0 is not between 1 and 255"},
+		{"x", maxUOctet, 0, `"x" is not a number`},
+		{"", maxShortFormPart, 0, `"" is not a number`},
+	}
+
+	for _, test := range tests {
+		n, err := parseNumber(test.value, 1, test.max)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("parseNumber(%q) error = %v, want %s", test.value, err, test.err)
+			}
+			continue
+		}
+		if err != nil || n != test.want {
+			t.Errorf("parseNumber(%q) = %d, %v, want %d", test.value, n, err, test.want)
+		}
+	}
+}
+
+func TestShortFormPartRange(t *testing.T) {
+	tests := []struct {
+		name      string
+		dataTypes string
+		problems  []string
+	}{
+		{
+			name:      "maximum",
+			dataTypes: composite("Last", "8388607", "Composite"),
+		},
+		{
+			name:      "too large",
+			dataTypes: composite("Large", "8388608", "Composite"),
+			problems:  []string{"TEST::Test::Large: short form part 8388608 is not between 1 and 8388607"},
+		},
+		{
+			name:      "not a number",
+			dataTypes: composite("Named", "two", "Composite"),
+			problems:  []string{`TEST::Test::Named: short form part "two" is not a number`},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := loadTestArea(t, "", test.dataTypes)
+			checkProblems(t, err, test.problems)
+		})
+	}
+}
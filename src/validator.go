@@ -0,0 +1,193 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2018 CNES
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package src
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/etiennelndr/archiveservice_generator/data"
+)
+
+// Validate resolves the types referenced by the model and checks that
+// it can be generated. An error listing all the problems found, each
+// with the path of the element in the specification, is returned if
+// the model is invalid. The problems start with the position of the
+// element when it is known.
+func (g *Generator) Validate() error {
+	// The numbers which can't be parsed are found while the model is
+	// built
+	problems := append([]string(nil), g.problems...)
+	problems = append(problems, g.resolveTypes()...)
+	for _, area := range g.Areas {
+		problems = append(problems, g.validateArea(area)...)
+	}
+
+	if len(problems) != 0 {
+		return errors.New("invalid specification:\n\t" + strings.Join(problems, "\n\t"))
+	}
+	return nil
+}
+
+// validator collects the problems found in an area
+type validator struct {
+	g        *Generator
+	problems []string
+}
+
+func (v *validator) report(pos data.Position, path string, format string, args ...interface{}) {
+	v.problems = append(v.problems, problem(pos, path, format, args...))
+}
+
+// problem formats a problem found in the element at pos whose path in
+// the specification is path, e.g.
+// "ServiceDefCOM.xml:87:9: COM::Archive::count: unknown type ..."
+func problem(pos data.Position, path string, format string, args ...interface{}) string {
+	message := path + ": " + fmt.Sprintf(format, args...)
+	if pos.IsValid() {
+		return pos.String() + ": " + message
+	}
+	return message
+}
+
+// validateArea checks an area and its services
+func (g *Generator) validateArea(area Area) []string {
+	v := &validator{g: g}
+	v.errors(area.Name, area.Errors)
+
+	// The short form parts identify the types of the area
+	shortForms := map[int32]string{}
+	for _, a := range area.Attributes {
+		v.shortFormPart(a.Pos, area.Name+"::"+a.Name, a.shortFormPart, shortForms)
+	}
+	v.data(area.Name, area.Composites, area.Enumerations, shortForms)
+
+	for _, s := range area.Services {
+		path := area.Name + "::" + s.Name
+		v.data(path, s.Composites, s.Enumerations, map[int32]string{})
+		v.errors(path, s.Errors)
+		// The objects and the events share their numbers
+		objects := map[string]string{}
+		for _, o := range append(s.Objects, s.Events...) {
+			v.number(o.Pos, path+"::"+o.Name, "object number", o.Number, 1, maxUShort)
+			if other, ok := objects[o.Number]; ok {
+				v.report(o.Pos, path+"::"+o.Name, "object number %s is already used by %s", o.Number, other)
+				continue
+			}
+			objects[o.Number] = o.Name
+		}
+
+		numbers := map[string]string{}
+		for _, op := range s.Operations {
+			v.number(op.Pos, path+"::"+op.Name, "operation number", op.Number, 1, maxUShort)
+			for _, e := range op.Errors {
+				if !e.Reference {
+					v.number(e.Pos, path+"::"+op.Name+"::"+e.Name, "error number", e.Number, 0, maxUInteger)
+				}
+			}
+			if other, ok := numbers[op.Number]; ok {
+				v.report(op.Pos, path+"::"+op.Name, "operation number %s is already used by %s", op.Number, other)
+				continue
+			}
+			numbers[op.Number] = op.Name
+		}
+	}
+
+	return v.problems
+}
+
+// data checks the composites and the enumerations of an area or a
+// service, shortForms holds the short form parts already used in it
+func (v *validator) data(path string, composites []Composite, enumerations []Enumeration, shortForms map[int32]string) {
+	for _, c := range composites {
+		v.shortFormPart(c.Pos, path+"::"+c.Name, c.shortFormPart, shortForms)
+		// The abstract composites have no short form part, their parents
+		// must be abstract as well to be generated as interfaces
+		if c.IsAbstract() && c.NameOfTypeToExtend != "" {
+			parent, ok := v.g.LookupComposite(c.AreaOfTypeToExtend, c.ServiceOfTypeToExtend, c.NameOfTypeToExtend)
+			if ok && !parent.IsAbstract() {
+				v.report(c.Pos, path+"::"+c.Name, "abstract composite extends the concrete composite %s", c.NameOfTypeToExtend)
+			}
+		}
+		for _, f := range c.Fields {
+			// The lists of abstract types can only be sent as parts of
+			// the messages, where the concrete list is encoded with its
+			// short form
+			t := f.Type()
+			if t.IsList() && v.g.isAbstract(t) {
+				v.report(f.Pos, path+"::"+c.Name+"::"+f.Name, "list of the abstract type %s", t.Area+"::"+t.Name)
+			}
+		}
+	}
+
+	for _, e := range enumerations {
+		v.shortFormPart(e.Pos, path+"::"+e.Name, e.shortFormPart, shortForms)
+		values := map[string]bool{}
+		nvalues := map[string]string{}
+		for _, item := range e.Items {
+			itemPath := path + "::" + e.Name + "::" + item.Value
+			if values[item.Value] {
+				v.report(item.Pos, itemPath, "item is defined twice")
+			}
+			values[item.Value] = true
+			v.number(item.Pos, itemPath, "numeric value", item.NValue, 0, maxUInteger)
+			if other, ok := nvalues[item.NValue]; ok {
+				v.report(item.Pos, itemPath, "numeric value %s is already used by %s", item.NValue, other)
+				continue
+			}
+			nvalues[item.NValue] = item.Value
+		}
+	}
+}
+
+// shortFormPart checks that the short form part of a type is not
+// already used by another type, the abstract types have none and the
+// invalid ones are read as 0
+func (v *validator) shortFormPart(pos data.Position, path string, shortFormPart int32, shortForms map[int32]string) {
+	if shortFormPart == 0 {
+		return
+	}
+	if other, ok := shortForms[shortFormPart]; ok {
+		v.report(pos, path, "short form part %d is already used by %s", shortFormPart, other)
+		return
+	}
+	shortForms[shortFormPart] = path
+}
+
+// errors checks the numbers of the errors of an area or a service
+func (v *validator) errors(path string, errs []Error) {
+	for _, e := range errs {
+		v.number(e.Pos, path+"::"+e.Name, "error number", e.Number, 0, maxUInteger)
+	}
+}
+
+// number checks that a number of the specification is between min and
+// max, what names it in the problem
+func (v *validator) number(pos data.Position, path string, what string, value string, min int64, max int64) {
+	if _, err := parseNumber(value, min, max); err != nil {
+		v.report(pos, path, "%s %v", what, err)
+	}
+}
@@ -0,0 +1,140 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2018 CNES
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package src
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// sendOperation returns a send operation of the test service
+func sendOperation(name string, number string) string {
+	return `<mal:sendIP name="` + name + `" number="` + number + `" supportInReplay="false">
+          <mal:messages>
+            <mal:send/>
+          </mal:messages>
+        </mal:sendIP>
+`
+}
+
+func TestValidateDuplicates(t *testing.T) {
+	tests := []struct {
+		name       string
+		operations string
+		dataTypes  string
+		problems   []string
+	}{
+		{
+			name:       "valid",
+			operations: sendOperation("pong", "2"),
+			dataTypes:  composite("First", "2", "Composite") + composite("Second", "3", "Composite"),
+		},
+		{
+			name:      "short form part of a composite",
+			dataTypes: composite("First", "2", "Composite") + composite("Second", "2", "Composite"),
+			problems:  []string{"TEST::Test::Second: short form part 2 is already used by TEST::Test::First"},
+		},
+		{
+			name:      "short form part of an enumeration",
+			dataTypes: composite("First", "1", "Composite"),
+			problems:  []string{"TEST::Test::Kind: short form part 1 is already used by TEST::Test::First"},
+		},
+		{
+			name:      "abstract composites",
+			dataTypes: composite("First", "", "Composite") + composite("Second", "", "Composite"),
+		},
+		{
+			name:       "operation number",
+			operations: sendOperation("pong", "1"),
+			problems:   []string{"TEST::Test::pong: operation number 1 is already used by ping"},
+		},
+		{
+			name:       "operation numbers",
+			operations: sendOperation("pong", "2") + sendOperation("echo", "2") + sendOperation("again", "1"),
+			problems: []string{
+				"TEST::Test::echo: operation number 2 is already used by pong",
+				"TEST::Test::again: operation number 1 is already used by ping",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := loadTestArea(t, test.operations, test.dataTypes)
+			checkProblems(t, err, test.problems)
+		})
+	}
+}
+
+func TestValidateObjectNumbers(t *testing.T) {
+	content, err := os.ReadFile(comSpecification)
+	if err != nil {
+		t.Fatal(err)
+	}
+	com := string(content)
+
+	tests := []struct {
+		name     string
+		content  string
+		problems []string
+	}{
+		{
+			name:    "valid",
+			content: com,
+		},
+		{
+			name:     "event number",
+			content:  strings.Replace(com, `<com:event name="ObjectUpdated" number="2"`, `<com:event name="ObjectUpdated" number="1"`, 1),
+			problems: []string{"COM::Archive::ObjectUpdated: object number 1 is already used by ObjectStored"},
+		},
+		{
+			name:     "object and event number",
+			content:  strings.Replace(com, `<com:object name="OperationActivity" number="6">`, `<com:object name="OperationActivity" number="1">`, 1),
+			problems: []string{"COM::ActivityTracking::Release: object number 1 is already used by OperationActivity"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "ServiceDefCOM.xml")
+			err := os.WriteFile(path, []byte(test.content), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			g := new(Generator)
+			for _, file := range []string{malSpecification, path} {
+				err = g.OpenAndReadXML(file)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			g.RetrieveInformation()
+			checkProblems(t, g.Validate(), test.problems)
+		})
+	}
+}